@@ -50,6 +50,17 @@ func main() {
 				// Value: "template",
 				Usage: "javascript `FILE` with user custom functions",
 			},
+			&cli.StringFlag{
+				Name:    "numbers",
+				Aliases: []string{"n"},
+				// Value: "template",
+				Usage: "printf `FORMAT` numbers are written with by default, such as %.2f",
+			},
+			&cli.StringFlag{
+				Name:  "null",
+				Value: "null",
+				Usage: "`TEXT` null values are written as",
+			},
 			&cli.BoolFlag{
 				Name:    "verbose",
 				Aliases: []string{"v"},
@@ -90,6 +101,13 @@ func main() {
 				}
 			}
 
+			err := md.SetNumberFormat(cCtx.String("numbers"))
+
+			if err != nil {
+				panic(err.Error())
+			}
+
+			md.SetNullText(cCtx.String("null"))
 			logger.DeployLogger(cCtx.Bool("verbose"), os.Stdout)
 
 			out, err := processTemplateFile(templFile)
@@ -92,9 +92,10 @@ func fromJSONtoElement(jsonType jsonparser.ValueType) md.ElementType {
 		return md.Array
 	case jsonparser.Object:
 		return md.Object
+	case jsonparser.Null:
+		return md.Null
 	case jsonparser.Unknown:
 	case jsonparser.NotExist:
-	case jsonparser.Null:
 	default:
 		return md.NotExists
 	}
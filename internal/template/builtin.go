@@ -0,0 +1,375 @@
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// builtin is a function implemented in Go, which can be called from templates without a functions file.
+// It receives the values of the arguments, already evaluated in the context ctx.
+type builtin func(ctx *ASTContext, args []item) (any, ElementType, error)
+
+// builtins are the functions available to every template, by name.
+// They take priority over Javascript functions with the same name.
+var builtins = map[string]builtin{
+	"upper":    upperBuiltin,
+	"lower":    lowerBuiltin,
+	"title":    titleBuiltin,
+	"trim":     trimBuiltin,
+	"replace":  replaceBuiltin,
+	"split":    splitBuiltin,
+	"join":     joinBuiltin,
+	"substr":   substrBuiltin,
+	"truncate": truncateBuiltin,
+	"pad":      padBuiltin,
+	"repeat":   repeatBuiltin,
+	"length":   lengthBuiltin,
+
+	"parseDate":   parseDateBuiltin,
+	"date":        dateBuiltin,
+	"daysBetween": daysBetweenBuiltin,
+
+	"len":     lenBuiltin,
+	"keys":    keysBuiltin,
+	"values":  valuesBuiltin,
+	"first":   firstBuiltin,
+	"last":    lastBuiltin,
+	"sort":    sortBuiltin,
+	"unique":  uniqueBuiltin,
+	"reverse": reverseBuiltin,
+	"sum":     sumBuiltin,
+	"min":     minBuiltin,
+	"max":     maxBuiltin,
+
+	"format":    formatBuiltin,
+	"round":     roundBuiltin,
+	"thousands": thousandsBuiltin,
+}
+
+// checkArgs returns an error if the number of arguments given to function is not between min and max
+func checkArgs(function string, args []item, min int, max int) error {
+	if len(args) < min || len(args) > max {
+		if min == max {
+			return fmt.Errorf("Function %s expects %d arguments, got %d", function, min, len(args))
+		}
+		return fmt.Errorf("Function %s expects %d to %d arguments, got %d", function, min, max, len(args))
+	}
+
+	return nil
+}
+
+// stringArg returns the textual form of the argument at index i. Numbers, booleans and dates are converted to text,
+// arrays and objects give an error.
+func stringArg(function string, args []item, i int) (string, error) {
+	arg := args[i]
+
+	if arg.elemType == Array || arg.elemType == Object || arg.elemType == NotExists {
+		return "", fmt.Errorf("Argument %d of %s must be a string", i+1, function)
+	}
+
+	return valueText(arg.value, arg.elemType), nil
+}
+
+// intArg returns the argument at index i as an integer, giving an error if it is not a whole number
+func intArg(function string, args []item, i int) (int, error) {
+	arg := args[i]
+	fl, isNumber := arg.value.(float64)
+
+	if arg.elemType != Number || !isNumber || fl != math.Trunc(fl) {
+		return 0, fmt.Errorf("Argument %d of %s must be a whole number", i+1, function)
+	}
+
+	return int(fl), nil
+}
+
+// arrayText converts a slice into the textual representation of arrays, as returned by Getters
+func arrayText(values any) (string, error) {
+	text, err := json.Marshal(values)
+	return string(text), err
+}
+
+// upperBuiltin converts a string to upper case - upper(text)
+func upperBuiltin(ctx *ASTContext, args []item) (any, ElementType, error) {
+	if err := checkArgs("upper", args, 1, 1); err != nil {
+		return nil, NotExists, err
+	}
+
+	text, err := stringArg("upper", args, 0)
+	return strings.ToUpper(text), String, err
+}
+
+// lowerBuiltin converts a string to lower case - lower(text)
+func lowerBuiltin(ctx *ASTContext, args []item) (any, ElementType, error) {
+	if err := checkArgs("lower", args, 1, 1); err != nil {
+		return nil, NotExists, err
+	}
+
+	text, err := stringArg("lower", args, 0)
+	return strings.ToLower(text), String, err
+}
+
+// titleBuiltin converts the first letter of every word of a string to upper case - title(text)
+func titleBuiltin(ctx *ASTContext, args []item) (any, ElementType, error) {
+	if err := checkArgs("title", args, 1, 1); err != nil {
+		return nil, NotExists, err
+	}
+
+	text, err := stringArg("title", args, 0)
+	if err != nil {
+		return nil, NotExists, err
+	}
+
+	runes := []rune(text)
+
+	for i, r := range runes {
+		if i == 0 || unicode.IsSpace(runes[i-1]) {
+			runes[i] = unicode.ToUpper(r)
+		}
+	}
+
+	return string(runes), String, nil
+}
+
+// trimBuiltin removes the whitespace around a string, or the given characters - trim(text, [characters])
+func trimBuiltin(ctx *ASTContext, args []item) (any, ElementType, error) {
+	if err := checkArgs("trim", args, 1, 2); err != nil {
+		return nil, NotExists, err
+	}
+
+	text, err := stringArg("trim", args, 0)
+	if err != nil {
+		return nil, NotExists, err
+	}
+
+	cutset := whitespace
+
+	if len(args) == 2 {
+		cutset, err = stringArg("trim", args, 1)
+	}
+
+	return strings.Trim(text, cutset), String, err
+}
+
+// replaceBuiltin replaces every occurrence of a substring of a string by another - replace(text, old, new)
+func replaceBuiltin(ctx *ASTContext, args []item) (any, ElementType, error) {
+	if err := checkArgs("replace", args, 3, 3); err != nil {
+		return nil, NotExists, err
+	}
+
+	strs := make([]string, 3)
+
+	for i := range strs {
+		var err error
+		strs[i], err = stringArg("replace", args, i)
+
+		if err != nil {
+			return nil, NotExists, err
+		}
+	}
+
+	return strings.ReplaceAll(strs[0], strs[1], strs[2]), String, nil
+}
+
+// splitBuiltin splits a string into an array of the substrings between separators - split(text, separator)
+func splitBuiltin(ctx *ASTContext, args []item) (any, ElementType, error) {
+	if err := checkArgs("split", args, 2, 2); err != nil {
+		return nil, NotExists, err
+	}
+
+	text, err := stringArg("split", args, 0)
+	if err != nil {
+		return nil, NotExists, err
+	}
+
+	separator, err := stringArg("split", args, 1)
+	if err != nil {
+		return nil, NotExists, err
+	}
+
+	array, err := arrayText(strings.Split(text, separator))
+	return array, Array, err
+}
+
+// joinBuiltin joins the items of an array into a string, with a separator between them - join(array, separator)
+func joinBuiltin(ctx *ASTContext, args []item) (any, ElementType, error) {
+	if err := checkArgs("join", args, 2, 2); err != nil {
+		return nil, NotExists, err
+	}
+
+	if args[0].elemType != Array {
+		return nil, NotExists, errors.New("Argument 1 of join must be an array")
+	}
+
+	separator, err := stringArg("join", args, 1)
+	if err != nil {
+		return nil, NotExists, err
+	}
+
+	items, err := members(ctx, args[0].value, Array)
+	if err != nil {
+		return nil, NotExists, err
+	}
+
+	texts := make([]string, len(items))
+
+	for i, item := range items {
+		texts[i] = valueText(item.value, item.elemType)
+	}
+
+	return strings.Join(texts, separator), String, nil
+}
+
+// substrBuiltin returns the part of a string starting at an index (from 0), up to the end of the string
+// or with the given length - substr(text, start, [length])
+func substrBuiltin(ctx *ASTContext, args []item) (any, ElementType, error) {
+	if err := checkArgs("substr", args, 2, 3); err != nil {
+		return nil, NotExists, err
+	}
+
+	text, err := stringArg("substr", args, 0)
+	if err != nil {
+		return nil, NotExists, err
+	}
+
+	start, err := intArg("substr", args, 1)
+	if err != nil {
+		return nil, NotExists, err
+	}
+
+	runes := []rune(text)
+	start = min(max(start, 0), len(runes))
+	end := len(runes)
+
+	if len(args) == 3 {
+		length, err := intArg("substr", args, 2)
+		if err != nil {
+			return nil, NotExists, err
+		}
+
+		end = min(start+max(length, 0), len(runes))
+	}
+
+	return string(runes[start:end]), String, nil
+}
+
+// truncateBuiltin shortens a string longer than a length, ending it with "..." or the given suffix.
+// The suffix counts towards the length - truncate(text, length, [suffix])
+func truncateBuiltin(ctx *ASTContext, args []item) (any, ElementType, error) {
+	if err := checkArgs("truncate", args, 2, 3); err != nil {
+		return nil, NotExists, err
+	}
+
+	text, err := stringArg("truncate", args, 0)
+	if err != nil {
+		return nil, NotExists, err
+	}
+
+	length, err := intArg("truncate", args, 1)
+	if err != nil {
+		return nil, NotExists, err
+	}
+
+	suffix := "..."
+
+	if len(args) == 3 {
+		suffix, err = stringArg("truncate", args, 2)
+		if err != nil {
+			return nil, NotExists, err
+		}
+	}
+
+	runes := []rune(text)
+
+	if len(runes) <= length {
+		return text, String, nil
+	}
+
+	kept := max(length-utf8.RuneCountInString(suffix), 0)
+	return string(runes[:kept]) + suffix, String, nil
+}
+
+// padBuiltin pads a string with spaces, or the given character, until it has the given length. As in printf,
+// positive lengths pad on the left (aligning to the right) and negative lengths pad on the right - pad(text, length, [character])
+func padBuiltin(ctx *ASTContext, args []item) (any, ElementType, error) {
+	if err := checkArgs("pad", args, 2, 3); err != nil {
+		return nil, NotExists, err
+	}
+
+	text, err := stringArg("pad", args, 0)
+	if err != nil {
+		return nil, NotExists, err
+	}
+
+	length, err := intArg("pad", args, 1)
+	if err != nil {
+		return nil, NotExists, err
+	}
+
+	fill := " "
+
+	if len(args) == 3 {
+		fill, err = stringArg("pad", args, 2)
+		if err != nil {
+			return nil, NotExists, err
+		}
+
+		if utf8.RuneCountInString(fill) != 1 {
+			return nil, NotExists, errors.New("Argument 3 of pad must be a single character")
+		}
+	}
+
+	missing := max(abs(length)-utf8.RuneCountInString(text), 0)
+	padding := strings.Repeat(fill, missing)
+
+	if length < 0 {
+		return text + padding, String, nil
+	}
+
+	return padding + text, String, nil
+}
+
+// repeatBuiltin repeats a string a number of times - repeat(text, count)
+func repeatBuiltin(ctx *ASTContext, args []item) (any, ElementType, error) {
+	if err := checkArgs("repeat", args, 2, 2); err != nil {
+		return nil, NotExists, err
+	}
+
+	text, err := stringArg("repeat", args, 0)
+	if err != nil {
+		return nil, NotExists, err
+	}
+
+	count, err := intArg("repeat", args, 1)
+	if err != nil {
+		return nil, NotExists, err
+	}
+
+	if count < 0 {
+		return nil, NotExists, errors.New("Argument 2 of repeat cannot be negative")
+	}
+
+	return strings.Repeat(text, count), String, nil
+}
+
+// lengthBuiltin returns the number of characters in a string - length(text)
+func lengthBuiltin(ctx *ASTContext, args []item) (any, ElementType, error) {
+	if err := checkArgs("length", args, 1, 1); err != nil {
+		return nil, NotExists, err
+	}
+
+	text, err := stringArg("length", args, 0)
+	return float64(utf8.RuneCountInString(text)), Number, err
+}
+
+// abs returns the absolute value of an integer
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,372 @@
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// item is a member of a collection, with its value already converted as an element's would be
+type item struct {
+	value    any
+	elemType ElementType
+}
+
+// arrayItems returns the items of an array, given as its textual representation
+func arrayItems(ctx *ASTContext, array string) ([]item, error) {
+	items := []item{}
+	var convErr error
+
+	forEach := func(curr []byte, dataType ElementType) {
+		v, err := typedStringToElem(string(curr), dataType)
+
+		if err != nil {
+			convErr = err
+		}
+
+		items = append(items, item{value: v, elemType: dataType})
+	}
+
+	if err := ctx.ArrayEach([]byte(array), forEach); err != nil {
+		return nil, err
+	}
+
+	return items, convErr
+}
+
+// objectKeys returns the property names of an object, given as its textual representation, as string items
+func objectKeys(ctx *ASTContext, object string) ([]item, error) {
+	keys := []item{}
+
+	forEach := func(prop string, val []byte, dataType ElementType) {
+		keys = append(keys, item{value: prop, elemType: String})
+	}
+
+	if err := ctx.ObjectEach([]byte(object), forEach); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// objectValues returns the values of the properties of an object, given as its textual representation
+func objectValues(ctx *ASTContext, object string) ([]item, error) {
+	values := []item{}
+	var convErr error
+
+	forEach := func(prop string, val []byte, dataType ElementType) {
+		v, err := typedStringToElem(string(val), dataType)
+
+		if err != nil {
+			convErr = err
+		}
+
+		values = append(values, item{value: v, elemType: dataType})
+	}
+
+	if err := ctx.ObjectEach([]byte(object), forEach); err != nil {
+		return nil, err
+	}
+
+	return values, convErr
+}
+
+// itemsText converts items into the textual representation of an array, as returned by Getters
+func itemsText(items []item) (string, error) {
+	values := make([]any, len(items))
+
+	for i, item := range items {
+		switch item.elemType {
+		case Array, Object:
+			text, _ := item.value.(string)
+			values[i] = json.RawMessage(text)
+		case Date:
+			values[i] = valueText(item.value, item.elemType)
+		default:
+			values[i] = item.value
+		}
+	}
+
+	return arrayText(values)
+}
+
+// compareItems compares two items, returning 0 if equal, -1 if a < b or 1 if a > b.
+// Only numbers, strings and dates can be compared, and only to items of the same type.
+func compareItems(a item, b item) (int8, error) {
+	if a.elemType != b.elemType {
+		return 0, errors.New("Only items of the same type can be compared")
+	}
+
+	switch a.elemType {
+	case Number:
+		return numberCompare(a.value, b.value), nil
+	case Date:
+		return dateCompare(a.value, b.value), nil
+	case String:
+		first, _ := a.value.(string)
+		second, _ := b.value.(string)
+		return int8(strings.Compare(first, second)), nil
+	}
+
+	return 0, errors.New("Only numbers, strings and dates can be compared")
+}
+
+// arrayArg returns the items of the argument at index i, giving an error if it is not an array
+func arrayArg(ctx *ASTContext, function string, args []item, i int) ([]item, error) {
+	if args[i].elemType != Array {
+		return nil, fmt.Errorf("Argument %d of %s must be an array", i+1, function)
+	}
+
+	text, _ := args[i].value.(string)
+	return arrayItems(ctx, text)
+}
+
+// objectArg returns the textual representation of the argument at index i, giving an error if it is not an object
+func objectArg(function string, args []item, i int) (string, error) {
+	if args[i].elemType != Object {
+		return "", fmt.Errorf("Argument %d of %s must be an object", i+1, function)
+	}
+
+	text, _ := args[i].value.(string)
+	return text, nil
+}
+
+// arrayFunction reads the only argument of function as an array, returning its items
+func arrayFunction(ctx *ASTContext, function string, args []item) ([]item, error) {
+	if err := checkArgs(function, args, 1, 1); err != nil {
+		return nil, err
+	}
+
+	return arrayArg(ctx, function, args, 0)
+}
+
+// lenBuiltin returns the number of items of an array, properties of an object or characters of a string - len(value)
+func lenBuiltin(ctx *ASTContext, args []item) (any, ElementType, error) {
+	if err := checkArgs("len", args, 1, 1); err != nil {
+		return nil, NotExists, err
+	}
+
+	if args[0].elemType == String {
+		text, _ := args[0].value.(string)
+		return float64(utf8.RuneCountInString(text)), Number, nil
+	}
+
+	items, err := members(ctx, args[0].value, args[0].elemType)
+
+	if err != nil {
+		return nil, NotExists, err
+	}
+
+	return float64(len(items)), Number, nil
+}
+
+// keysBuiltin returns an array with the names of the properties of an object - keys(object)
+func keysBuiltin(ctx *ASTContext, args []item) (any, ElementType, error) {
+	if err := checkArgs("keys", args, 1, 1); err != nil {
+		return nil, NotExists, err
+	}
+
+	object, err := objectArg("keys", args, 0)
+	if err != nil {
+		return nil, NotExists, err
+	}
+
+	keys, err := objectKeys(ctx, object)
+	if err != nil {
+		return nil, NotExists, err
+	}
+
+	array, err := itemsText(keys)
+	return array, Array, err
+}
+
+// valuesBuiltin returns an array with the values of the properties of an object - values(object)
+func valuesBuiltin(ctx *ASTContext, args []item) (any, ElementType, error) {
+	if err := checkArgs("values", args, 1, 1); err != nil {
+		return nil, NotExists, err
+	}
+
+	object, err := objectArg("values", args, 0)
+	if err != nil {
+		return nil, NotExists, err
+	}
+
+	values, err := objectValues(ctx, object)
+	if err != nil {
+		return nil, NotExists, err
+	}
+
+	array, err := itemsText(values)
+	return array, Array, err
+}
+
+// firstBuiltin returns the first item of an array, giving an error if it is empty - first(array)
+func firstBuiltin(ctx *ASTContext, args []item) (any, ElementType, error) {
+	items, err := arrayFunction(ctx, "first", args)
+	if err != nil {
+		return nil, NotExists, err
+	}
+
+	if len(items) == 0 {
+		return nil, NotExists, errors.New("Cannot get the first item of an empty array")
+	}
+
+	return items[0].value, items[0].elemType, nil
+}
+
+// lastBuiltin returns the last item of an array, giving an error if it is empty - last(array)
+func lastBuiltin(ctx *ASTContext, args []item) (any, ElementType, error) {
+	items, err := arrayFunction(ctx, "last", args)
+	if err != nil {
+		return nil, NotExists, err
+	}
+
+	if len(items) == 0 {
+		return nil, NotExists, errors.New("Cannot get the last item of an empty array")
+	}
+
+	last := items[len(items)-1]
+	return last.value, last.elemType, nil
+}
+
+// sortBuiltin returns an array with the items of another in ascending order.
+// The items must all be numbers, strings or dates - sort(array)
+func sortBuiltin(ctx *ASTContext, args []item) (any, ElementType, error) {
+	items, err := arrayFunction(ctx, "sort", args)
+	if err != nil {
+		return nil, NotExists, err
+	}
+
+	var sortErr error
+
+	slices.SortStableFunc(items, func(a, b item) int {
+		r, err := compareItems(a, b)
+
+		if err != nil {
+			sortErr = err
+		}
+
+		return int(r)
+	})
+
+	if sortErr != nil {
+		return nil, NotExists, sortErr
+	}
+
+	array, err := itemsText(items)
+	return array, Array, err
+}
+
+// uniqueBuiltin returns an array with the items of another without repetitions, keeping their first occurrence - unique(array)
+func uniqueBuiltin(ctx *ASTContext, args []item) (any, ElementType, error) {
+	items, err := arrayFunction(ctx, "unique", args)
+	if err != nil {
+		return nil, NotExists, err
+	}
+
+	unique := []item{}
+
+	for _, it := range items {
+		repeated := slices.ContainsFunc(unique, func(other item) bool {
+			return equalValues(it.value, it.elemType, other.value, other.elemType)
+		})
+
+		if !repeated {
+			unique = append(unique, it)
+		}
+	}
+
+	array, err := itemsText(unique)
+	return array, Array, err
+}
+
+// reverseBuiltin returns an array with the items of another in reverse order - reverse(array)
+func reverseBuiltin(ctx *ASTContext, args []item) (any, ElementType, error) {
+	items, err := arrayFunction(ctx, "reverse", args)
+	if err != nil {
+		return nil, NotExists, err
+	}
+
+	slices.Reverse(items)
+
+	array, err := itemsText(items)
+	return array, Array, err
+}
+
+// sumBuiltin returns the sum of the items of an array, which must all be numbers - sum(array)
+func sumBuiltin(ctx *ASTContext, args []item) (any, ElementType, error) {
+	items, err := arrayFunction(ctx, "sum", args)
+	if err != nil {
+		return nil, NotExists, err
+	}
+
+	total := 0.0
+
+	for _, it := range items {
+		number, isNumber := it.value.(float64)
+
+		if it.elemType != Number || !isNumber {
+			return nil, NotExists, errors.New("Only arrays of numbers can be summed")
+		}
+
+		total += number
+	}
+
+	return total, Number, nil
+}
+
+// extremeItem returns the item of an array that compares with every other as wanted (-1 for the minimum, 1 for the maximum)
+func extremeItem(ctx *ASTContext, function string, args []item, wanted int8) (any, ElementType, error) {
+	items, err := arrayFunction(ctx, function, args)
+	if err != nil {
+		return nil, NotExists, err
+	}
+
+	if len(items) == 0 {
+		return nil, NotExists, fmt.Errorf("Cannot get the %s of an empty array", function)
+	}
+
+	extreme := items[0]
+
+	for _, it := range items[1:] {
+		r, err := compareItems(it, extreme)
+
+		if err != nil {
+			return nil, NotExists, err
+		}
+
+		if r == wanted {
+			extreme = it
+		}
+	}
+
+	return extreme.value, extreme.elemType, nil
+}
+
+// minBuiltin returns the smallest of the items of an array, which must all be numbers, strings or dates - min(array)
+func minBuiltin(ctx *ASTContext, args []item) (any, ElementType, error) {
+	return extremeItem(ctx, "min", args, -1)
+}
+
+// maxBuiltin returns the largest of the items of an array, which must all be numbers, strings or dates - max(array)
+func maxBuiltin(ctx *ASTContext, args []item) (any, ElementType, error) {
+	return extremeItem(ctx, "max", args, 1)
+}
+
+// members returns the members of a collection value - the items of an array or the keys of an object.
+// Returns an error if the value is not a collection.
+func members(ctx *ASTContext, collection any, collectionType ElementType) ([]item, error) {
+	text, _ := collection.(string)
+
+	switch collectionType {
+	case Array:
+		return arrayItems(ctx, text)
+	case Object:
+		return objectKeys(ctx, text)
+	}
+
+	return nil, errors.New("Value is not an array or an object")
+}
@@ -2,6 +2,8 @@ package parser
 
 import (
 	"errors"
+	"regexp"
+	"strings"
 )
 
 // operatorType defines the operation comparison conditions (>, <, =, etc.)
@@ -25,6 +27,18 @@ const eq operatorType = 4
 // dif means the comparison is a difference (a != b)
 const dif operatorType = 5
 
+// in means the comparison checks whether a is a member of b (a in b)
+const in operatorType = 6
+
+// contains means the comparison checks whether b is a member of a (a contains b)
+const contains operatorType = 7
+
+// startsWith means the comparison checks whether a starts with b (a startswith b)
+const startsWith operatorType = 8
+
+// endsWith means the comparison checks whether a ends with b (a endswith b)
+const endsWith operatorType = 9
+
 // convertOperator takes a string operator and returns the correct type
 func convertOperator(operator string) operatorType {
 	switch operator {
@@ -40,6 +54,14 @@ func convertOperator(operator string) operatorType {
 		return lt
 	case "!=":
 		return dif
+	case "in":
+		return in
+	case "contains":
+		return contains
+	case "startswith":
+		return startsWith
+	case "endswith":
+		return endsWith
 	}
 	return 0
 }
@@ -149,8 +171,9 @@ type operatorCondition struct {
 }
 
 // eval on operatorCondition checks whether the comparison is possible and performs it.
-// returns an error if the elements are of different types or if types other than numbers
-// are compared with a non-equal operator
+// returns an error if the elements are of different types or if types other than numbers and dates
+// are compared with an ordering operator (<, <=, > or >=). Membership operators are the
+// exception, as they compare collections with the values in them.
 func (c operatorCondition) eval(ctx *ASTContext) (bool, error) {
 
 	v1, tpe1, err := c.left.value(ctx)
@@ -165,28 +188,61 @@ func (c operatorCondition) eval(ctx *ASTContext) (bool, error) {
 		return false, err
 	}
 
+	switch c.operator {
+	case in:
+		return membership(ctx, contains, v2, tpe2, v1, tpe1)
+	case contains, startsWith, endsWith:
+		return membership(ctx, c.operator, v1, tpe1, v2, tpe2)
+	}
+
+	// strings compared with dates are read as dates
+	if tpe1 == Date && tpe2 == String {
+		v2, err = toDate(v2, tpe2, nil)
+		tpe2 = Date
+	} else if tpe1 == String && tpe2 == Date {
+		v1, err = toDate(v1, tpe1, nil)
+		tpe1 = Date
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	// anything can be checked for being null
+	if (tpe1 == Null || tpe2 == Null) && (c.operator == eq || c.operator == dif) {
+		return equalValues(v1, tpe1, v2, tpe2) == (c.operator == eq), nil
+	}
+
 	if tpe1 != tpe2 {
 		return false, errors.New("Values must be of the same type")
 	}
 
-	if c.operator != eq && tpe1 != Number {
-		return false, errors.New("Only numbers can have non-equal comparisons")
+	if c.operator != eq && c.operator != dif && tpe1 != Number && tpe1 != Date {
+		return false, errors.New("Only numbers and dates can have non-equal comparisons")
+	}
+
+	compare := numberCompare
+
+	if tpe1 == Date {
+		compare = dateCompare
 	}
 
 	switch c.operator {
 	case eq:
-		return v1 == v2, nil
+		return equalValues(v1, tpe1, v2, tpe2), nil
+	case dif:
+		return !equalValues(v1, tpe1, v2, tpe2), nil
 	case lt:
-		r := numberCompare(v1, v2)
+		r := compare(v1, v2)
 		return r < 0, nil
 	case lte:
-		r := numberCompare(v1, v2)
+		r := compare(v1, v2)
 		return r <= 0, nil
 	case gt:
-		r := numberCompare(v1, v2)
+		r := compare(v1, v2)
 		return r > 0, nil
 	case gte:
-		r := numberCompare(v1, v2)
+		r := compare(v1, v2)
 		return r >= 0, nil
 
 	}
@@ -195,6 +251,78 @@ func (c operatorCondition) eval(ctx *ASTContext) (bool, error) {
 
 }
 
+// membership checks whether a collection contains (or starts or ends with) a value, according to operator.
+// Strings are searched for substrings, arrays for an equal item and objects for a property with the value as key.
+func membership(ctx *ASTContext, operator operatorType, collection any, collectionType ElementType, searched any, searchedType ElementType) (bool, error) {
+
+	if collectionType == String {
+		if searchedType != String {
+			return false, errors.New("Only strings can be searched in strings")
+		}
+
+		text, _ := collection.(string)
+		substring, _ := searched.(string)
+
+		switch operator {
+		case startsWith:
+			return strings.HasPrefix(text, substring), nil
+		case endsWith:
+			return strings.HasSuffix(text, substring), nil
+		default:
+			return strings.Contains(text, substring), nil
+		}
+	}
+
+	items, err := members(ctx, collection, collectionType)
+
+	if err != nil {
+		return false, err
+	}
+
+	if len(items) == 0 {
+		return false, nil
+	}
+
+	switch operator {
+	case startsWith:
+		first := items[0]
+		return equalValues(first.value, first.elemType, searched, searchedType), nil
+	case endsWith:
+		last := items[len(items)-1]
+		return equalValues(last.value, last.elemType, searched, searchedType), nil
+	}
+
+	for _, item := range items {
+		if equalValues(item.value, item.elemType, searched, searchedType) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// matchesCondition checks whether the value of an element matches a regular expression
+type matchesCondition struct {
+	element element
+	regex   *regexp.Regexp
+}
+
+// eval on matchesCondition checks whether the element is a string matching the regular expression.
+// Returns an error if the element is not a string.
+func (c matchesCondition) eval(ctx *ASTContext) (bool, error) {
+	v, tpe, err := c.element.value(ctx)
+
+	if err != nil {
+		return false, err
+	}
+
+	if tpe != String {
+		return false, errors.New("Only strings can be matched against regular expressions")
+	}
+
+	return c.regex.MatchString(v.(string)), nil
+}
+
 // existsCondition is a condition that checks whether a given element exists,
 // meant as a variable verification prior to an access if there is a chance
 // that variable might not exist
@@ -202,7 +330,7 @@ type existsCondition struct {
 	element element
 }
 
-// eval on existsCondition returns true if the element exists, false otherwise.
+// eval on existsCondition returns true if the element exists (even if it is null), false otherwise.
 // constants will always return true.
 func (n existsCondition) eval(ctx *ASTContext) (bool, error) {
 	_, tpe, err := n.element.value(ctx)
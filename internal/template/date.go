@@ -0,0 +1,158 @@
+package parser
+
+import (
+	"fmt"
+	"time"
+)
+
+// dateLayouts are the layouts dates are parsed with, in order, when no layouts are given
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02",
+	"02-01-2006",
+	"02/01/2006",
+	"02.01.2006",
+	"January 2, 2006",
+	"2 January 2006",
+	"Jan 2, 2006",
+	"2 Jan 2006",
+}
+
+// dayLayout is the layout of dates without a time of day
+const dayLayout = "2006-01-02"
+
+// dateText converts a date into its textual form - only the day for dates at midnight UTC, RFC 3339 otherwise
+func dateText(date time.Time) string {
+	if date.Location() == time.UTC && date.Equal(date.Truncate(24*time.Hour)) {
+		return date.Format(dayLayout)
+	}
+
+	return date.Format(time.RFC3339Nano)
+}
+
+// parseDate parses text with the first of layouts that matches it.
+// Returns an error if none does.
+func parseDate(text string, layouts []string) (time.Time, error) {
+	for _, layout := range layouts {
+		if date, err := time.Parse(layout, text); err == nil {
+			return date, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%s is not a date in any of the layouts %v", text, layouts)
+}
+
+// toDate converts a value into a date. Dates are kept, strings are parsed with layouts (or the default layouts
+// if there are none) and numbers are milliseconds since the Unix epoch. Other values give an error.
+func toDate(v any, tpe ElementType, layouts []string) (time.Time, error) {
+	if len(layouts) == 0 {
+		layouts = dateLayouts
+	}
+
+	switch tpe {
+	case Date:
+		date, _ := v.(time.Time)
+		return date, nil
+	case String:
+		text, _ := v.(string)
+		return parseDate(text, layouts)
+	case Number:
+		millis, _ := v.(float64)
+		return time.UnixMilli(int64(millis)).UTC(), nil
+	}
+
+	return time.Time{}, fmt.Errorf("%s cannot be converted to a date", valueText(v, tpe))
+}
+
+// dateCompare compares two dates in the form of any variables
+// if a > b it returns 1
+// if a < b it returns -1
+// if a = b it returns 0
+func dateCompare(a, b any) int8 {
+	actualA, _ := a.(time.Time)
+	actualB, _ := b.(time.Time)
+
+	return int8(actualA.Compare(actualB))
+}
+
+// dateArg returns the argument at index i as a date, see toDate
+func dateArg(function string, args []item, i int) (time.Time, error) {
+	date, err := toDate(args[i].value, args[i].elemType, nil)
+
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Argument %d of %s must be a date: %w", i+1, function, err)
+	}
+
+	return date, nil
+}
+
+// parseDateBuiltin converts a string into a date, trying each of the given layouts (or the default ones) in order,
+// or a number of milliseconds since the Unix epoch - parseDate(value, [layout, ...])
+func parseDateBuiltin(ctx *ASTContext, args []item) (any, ElementType, error) {
+	if err := checkArgs("parseDate", args, 1, max(len(args), 1)); err != nil {
+		return nil, NotExists, err
+	}
+
+	layouts := make([]string, len(args)-1)
+
+	for i := range layouts {
+		var err error
+		layouts[i], err = stringArg("parseDate", args, i+1)
+
+		if err != nil {
+			return nil, NotExists, err
+		}
+	}
+
+	date, err := toDate(args[0].value, args[0].elemType, layouts)
+
+	if err != nil {
+		return nil, NotExists, err
+	}
+
+	return date, Date, nil
+}
+
+// dateBuiltin formats a date (or a value parseDate accepts with the default layouts) with a layout - date(value, layout)
+func dateBuiltin(ctx *ASTContext, args []item) (any, ElementType, error) {
+	if err := checkArgs("date", args, 2, 2); err != nil {
+		return nil, NotExists, err
+	}
+
+	date, err := dateArg("date", args, 0)
+	if err != nil {
+		return nil, NotExists, err
+	}
+
+	layout, err := stringArg("date", args, 1)
+	if err != nil {
+		return nil, NotExists, err
+	}
+
+	return date.Format(layout), String, nil
+}
+
+// daysBetweenBuiltin returns the number of whole days from one date to another, negative if the second
+// is before the first - daysBetween(from, to)
+func daysBetweenBuiltin(ctx *ASTContext, args []item) (any, ElementType, error) {
+	if err := checkArgs("daysBetween", args, 2, 2); err != nil {
+		return nil, NotExists, err
+	}
+
+	from, err := dateArg("daysBetween", args, 0)
+	if err != nil {
+		return nil, NotExists, err
+	}
+
+	to, err := dateArg("daysBetween", args, 1)
+	if err != nil {
+		return nil, NotExists, err
+	}
+
+	days := to.Sub(from) / (24 * time.Hour)
+	return float64(days), Number, nil
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // ElementType defines the type of an element
@@ -15,6 +16,16 @@ const Number ElementType = 2
 const Array ElementType = 3
 const Object ElementType = 4
 const NotExists ElementType = 5
+const Date ElementType = 6
+const Null ElementType = 7
+
+// nullText is the text null values are written as in the output of templates
+var nullText = "null"
+
+// SetNullText sets the text null values are written as in the output of templates, such as "" or "n/a"
+func SetNullText(text string) {
+	nullText = text
+}
 
 // An element represents a value in the tree - it can be any value. It can refer to functions,
 // variable accesses or constants.
@@ -29,7 +40,7 @@ type element interface {
 	stringValue(ctx *ASTContext) (string, error)
 }
 
-// convertType converts a text type definition (array, bool, object, number or string)
+// convertType converts a text type definition (array, bool, object, number, string, date or null)
 // into its matching ElementType
 func convertType(text string) ElementType {
 	if text == "array" {
@@ -42,6 +53,10 @@ func convertType(text string) ElementType {
 		return String
 	} else if text == "bool" {
 		return Boolean
+	} else if text == "date" {
+		return Date
+	} else if text == "null" {
+		return Null
 	}
 	return NotExists
 }
@@ -57,6 +72,8 @@ func textToElem(text string) (any, ElementType, error) {
 		return true, Boolean, nil
 	} else if text == "false" {
 		return false, Boolean, nil
+	} else if text == "null" {
+		return nil, Null, nil
 	} else {
 		fl, err := strconv.ParseFloat(text, 64)
 		if err != nil {
@@ -69,8 +86,8 @@ func textToElem(text string) (any, ElementType, error) {
 
 }
 
-// anyElemToString converts the value returned by an element (any), into a string
-// when the element is of type String, Boolean or Number.
+// anyElemToString converts the value returned by an element (any), into the string written in
+// the output of templates. Numbers are written with the default number format.
 func anyElemToString(elem any, elementType ElementType) (string, error) {
 	if elementType == String {
 		s := elem.(string)
@@ -80,10 +97,46 @@ func anyElemToString(elem any, elementType ElementType) (string, error) {
 		return strconv.FormatBool(b), nil
 	} else if elementType == Number {
 		fl := elem.(float64)
-		s := strconv.FormatFloat(fl, 'f', 2, 64)
-		return s, nil
+		return numberText(fl), nil
+	} else if elementType == Date {
+		return dateText(elem.(time.Time)), nil
+	} else if elementType == Null {
+		return nullText, nil
+	}
+	text, _ := elem.(string)
+	return text, nil
+}
+
+// valueText converts the value returned by an element back into the textual form returned by Getters.
+// Unlike anyElemToString, numbers keep the precision they have, regardless of the default number format.
+func valueText(elem any, elementType ElementType) string {
+	switch elementType {
+	case Number:
+		fl, _ := elem.(float64)
+		return strconv.FormatFloat(fl, 'f', -1, 64)
+	case Boolean:
+		b, _ := elem.(bool)
+		return strconv.FormatBool(b)
+	case Date:
+		date, _ := elem.(time.Time)
+		return dateText(date)
+	case Null:
+		return "null"
+	}
+
+	text, _ := elem.(string)
+	return text
+}
+
+// elementText evaluates an element, returning its value in the textual form returned by Getters and its type
+func elementText(e element, ctx *ASTContext) (string, ElementType, error) {
+	v, tpe, err := e.value(ctx)
+
+	if err != nil {
+		return "", NotExists, err
 	}
-	return elem.(string), nil
+
+	return valueText(v, tpe), tpe, nil
 }
 
 // typedStringToElem takes a string that has already been classified and converts it to its
@@ -103,10 +156,24 @@ func typedStringToElem(text string, elemenType ElementType) (any, error) {
 			return nil, err
 		}
 		return fl, nil
+	} else if elemenType == Date {
+		return parseDate(text, []string{dayLayout, time.RFC3339Nano})
+	} else if elemenType == Null {
+		return nil, nil
 	}
 	return text, nil
 }
 
+// equalValues checks whether two values, as returned by elements, are equal.
+// Values of different types are never equal, and dates are equal if they are the same instant.
+func equalValues(v1 any, tpe1 ElementType, v2 any, tpe2 ElementType) bool {
+	if tpe1 == Date && tpe2 == Date {
+		return dateCompare(v1, v2) == 0
+	}
+
+	return tpe1 == tpe2 && v1 == v2
+}
+
 // compare takes two elements of any kind and compares the two, returning 0 if equal, -1 if e < other or
 // 1 if e > other.
 //
@@ -144,6 +211,9 @@ func compare(e element, other element, ctx *ASTContext, actual ElementType) (int
 
 	case Number:
 		return numberCompare(thisVal, otherVal), nil
+
+	case Date:
+		return dateCompare(thisVal, otherVal), nil
 	}
 	return 0, errors.New("Unsuported comparison")
 }
@@ -190,12 +260,27 @@ func (e accessElement) value(ctx *ASTContext) (any, ElementType, error) {
 
 // stringValue finds and returns the variable matching the pattern, as a string
 // Improves efficency when directly accessing for a string, as it avoids the need for type conversions
+// of anything other than nulls and, when a default number format is set, numbers. Otherwise numbers are written
+// as they are in the data, so large integers and trailing zeros are kept.
 func (e accessElement) stringValue(ctx *ASTContext) (string, error) {
-	elem, _, err := getPattern(e.pattern, ctx)
+	elem, tpe, err := getPattern(e.pattern, ctx)
 	if err != nil {
 		return "", err
 	}
 
+	if tpe == Null {
+		return nullText, nil
+	}
+
+	if tpe == Number && numberFormat != "" {
+		fl, err := strconv.ParseFloat(elem, 64)
+		if err != nil {
+			return "", err
+		}
+
+		return numberText(fl), nil
+	}
+
 	return elem, err
 }
 
@@ -206,9 +291,19 @@ type constantElement struct {
 	constant string
 }
 
-// stringValue is  textual representation of the constant (how it was extracted from the text)
+// stringValue is  textual representation of the constant (how it was extracted from the text),
+// without the quotes in the case of strings. Numbers are written with the default number format.
 func (e constantElement) stringValue(ctx *ASTContext) (string, error) {
-	return e.constant, nil
+	if e.constant[0] == '"' {
+		return e.constant[1 : len(e.constant)-1], nil
+	}
+
+	v, tpe, err := textToElem(e.constant)
+	if err != nil {
+		return "", err
+	}
+
+	return anyElemToString(v, tpe)
 }
 
 // value of the constant, already parsed in case of floats or bools
@@ -216,6 +311,132 @@ func (e constantElement) value(ctx *ASTContext) (any, ElementType, error) {
 	return textToElem(e.constant)
 }
 
+// coalesceElement represents an element with a fallback, used when the element's value is missing or null
+type coalesceElement struct {
+	// element is used when it exists
+	element element
+
+	// fallback is the element used when value does not exist
+	fallback element
+}
+
+// value of a coalesceElement is the value of its element, or the value of the fallback if it does not exist
+// or is null. Elements do not exist if their type is NotExists or evaluating them results in an error.
+func (e coalesceElement) value(ctx *ASTContext) (any, ElementType, error) {
+	v, tpe, err := e.element.value(ctx)
+
+	if err != nil || tpe == NotExists || tpe == Null {
+		return e.fallback.value(ctx)
+	}
+
+	return v, tpe, nil
+}
+
+// stringValue is the textual representation of the element, or of the fallback if it does not exist
+func (e coalesceElement) stringValue(ctx *ASTContext) (string, error) {
+	v, tpe, err := e.value(ctx)
+
+	if err != nil {
+		return "", err
+	}
+
+	return anyElemToString(v, tpe)
+}
+
+// concatenation represents the joining of the text of several elements into a string
+type concatenation struct {
+	// parts are the elements joined, in order
+	parts []element
+}
+
+// value of a concatenation is the string with the text of each part, as written in the output of templates.
+// Returns an error if any part is an array or an object.
+func (e concatenation) value(ctx *ASTContext) (any, ElementType, error) {
+	sb := strings.Builder{}
+
+	for _, part := range e.parts {
+		v, tpe, err := part.value(ctx)
+
+		if err != nil {
+			return nil, NotExists, err
+		}
+
+		if tpe == Array || tpe == Object || tpe == NotExists {
+			return nil, NotExists, errors.New("Only strings, numbers, booleans and dates can be concatenated")
+		}
+
+		text, err := anyElemToString(v, tpe)
+
+		if err != nil {
+			return nil, NotExists, err
+		}
+
+		sb.WriteString(text)
+	}
+
+	return sb.String(), String, nil
+}
+
+// stringValue of a concatenation is its value
+func (e concatenation) stringValue(ctx *ASTContext) (string, error) {
+	v, _, err := e.value(ctx)
+
+	if err != nil {
+		return "", err
+	}
+
+	return v.(string), nil
+}
+
+// ternaryElement represents the choice between two elements, depending on a condition
+type ternaryElement struct {
+	// condition deciding the element to use
+	condition condition
+
+	// whenTrue is used if the condition is true
+	whenTrue element
+
+	// whenFalse is used if the condition is false
+	whenFalse element
+}
+
+// pick evaluates the condition and returns the matching element
+func (e ternaryElement) pick(ctx *ASTContext) (element, error) {
+	result, err := e.condition.eval(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if result {
+		return e.whenTrue, nil
+	}
+
+	return e.whenFalse, nil
+}
+
+// value of a ternaryElement is the value of the element chosen by the condition
+func (e ternaryElement) value(ctx *ASTContext) (any, ElementType, error) {
+	chosen, err := e.pick(ctx)
+
+	if err != nil {
+		return nil, NotExists, err
+	}
+
+	return chosen.value(ctx)
+}
+
+// stringValue is the textual representation of the element chosen by the condition
+func (e ternaryElement) stringValue(ctx *ASTContext) (string, error) {
+	chosen, err := e.pick(ctx)
+
+	if err != nil {
+		return "", err
+	}
+
+	return chosen.stringValue(ctx)
+}
+
 // numberCompares compares to numbers in the form of any variables
 // if a > b it returns 1
 // if a < b it returns -1
@@ -73,14 +73,42 @@ func ottoToElemType(v *otto.Value) (any, ElementType, error) {
 	} else if v.IsObject() {
 		val, err = v.ToString()
 		tpe = Object
+	} else if v.IsNull() {
+		tpe = Null
 	}
 
 	return val, tpe, err
 }
 
+// callBuiltin evaluates the parameters of the function and calls the built-in function with their values
+func (f userFunc) callBuiltin(ctx *ASTContext, function builtin) (any, ElementType, error) {
+	args := make([]item, len(f.parameters))
+
+	for i, par := range f.parameters {
+		v, tpe, err := par.value(ctx)
+
+		if err != nil {
+			return nil, NotExists, err
+		}
+
+		args[i] = item{value: v, elemType: tpe}
+	}
+
+	return function(ctx, args)
+}
+
 // Returns the value, given a context ctx (in case variable accesses are necessary) and
-// its element type.
+// its element type. Macros defined in the template take priority over built-in functions,
+// which take priority over Javascript functions.
 func (f userFunc) value(ctx *ASTContext) (any, ElementType, error) {
+	if macro, isMacro := ctx.macros[f.name]; isMacro {
+		return macro.call(ctx, f.parameters)
+	}
+
+	if function, isBuiltin := builtins[f.name]; isBuiltin {
+		return f.callBuiltin(ctx, function)
+	}
+
 	v, err := f.call(ctx)
 	if err != nil {
 		return nil, NotExists, err
@@ -92,6 +120,10 @@ func (f userFunc) value(ctx *ASTContext) (any, ElementType, error) {
 // Returns the value, given a context ctx (in case variable accesses are necessary) and
 // its element type.
 func (f userFunc) stringValue(ctx *ASTContext) (string, error) {
-	v, _, e := f.value(ctx)
-	return v.(string), e
+	v, tpe, err := f.value(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return anyElemToString(v, tpe)
 }
@@ -2,20 +2,25 @@ package parser
 
 import (
 	"errors"
-	"fmt"
-	"strconv"
+	"math"
 )
 
-func textToOperator(text byte) mathOperator {
+func textToOperator(text string) mathOperator {
 	switch text {
-	case '+':
+	case "+":
 		return addition
-	case '-':
+	case "-":
 		return subtraction
-	case '*':
+	case "*":
 		return multiplication
-	case '/':
+	case "/":
 		return division
+	case "//":
+		return integerDivision
+	case "%":
+		return modulo
+	case "^":
+		return power
 	default:
 		return invalid
 	}
@@ -28,6 +33,9 @@ const subtraction mathOperator = 1
 const division mathOperator = 2
 const multiplication mathOperator = 3
 const invalid mathOperator = 4
+const integerDivision mathOperator = 5
+const modulo mathOperator = 6
+const power mathOperator = 7
 
 // expression represents a mathmatical expression between two elements
 type expression struct {
@@ -47,33 +55,43 @@ type expression struct {
 func getNumber(e element, ctx *ASTContext) (float64, error) {
 	val, tpe, err := e.value(ctx)
 
-	fmt.Println(val, tpe, err)
+	if err != nil {
+		return 0, err
+	}
 
 	if tpe != Number {
 		return 0, errors.New("Invalid mathmatical expression, can only operate over numbers")
 	}
 
-	if err != nil {
-		return 0, err
-	}
-
 	return val.(float64), nil
 }
 
 // performMath performs the appropriate mathmatical operation, defined by the operation argument
-// and using left and right arguments in their correct order.
-func performMath(left float64, right float64, operation mathOperator) float64 {
+// and using left and right arguments in their correct order. Integer divisions discard the decimal
+// part of the result, and the remainder of a modulo has the sign of left.
+// Returns an error on divisions by zero.
+func performMath(left float64, right float64, operation mathOperator) (float64, error) {
+	if right == 0 && (operation == division || operation == integerDivision || operation == modulo) {
+		return 0, errors.New("Division by zero")
+	}
+
 	switch operation {
 	case addition:
-		return left + right
+		return left + right, nil
 	case subtraction:
-		return left - right
+		return left - right, nil
 	case division:
-		return left / right
+		return left / right, nil
 	case multiplication:
-		return left * right
+		return left * right, nil
+	case integerDivision:
+		return math.Trunc(left / right), nil
+	case modulo:
+		return math.Mod(left, right), nil
+	case power:
+		return math.Pow(left, right), nil
 	}
-	return 0
+	return 0, errors.New("Invalid mathmatical operator")
 }
 
 // value of an expression evaluates the left side and checks that it is a number
@@ -91,7 +109,11 @@ func (e expression) value(ctx *ASTContext) (any, ElementType, error) {
 		return nil, NotExists, err
 	}
 
-	result := performMath(leftNumber, rightNumber, e.operation)
+	result, err := performMath(leftNumber, rightNumber, e.operation)
+
+	if err != nil {
+		return nil, NotExists, err
+	}
 
 	return result, Number, nil
 }
@@ -103,6 +125,32 @@ func (e expression) stringValue(ctx *ASTContext) (string, error) {
 		return "", err
 	}
 
-	return strconv.FormatFloat(v.(float64), 'f', -1, 64), nil
+	return numberText(v.(float64)), nil
+
+}
+
+// negatedElement represents the negation of a number (-element)
+type negatedElement struct {
+	element element
+}
+
+// value of a negatedElement evaluates the element, checks that it is a number and negates it
+func (e negatedElement) value(ctx *ASTContext) (any, ElementType, error) {
+	number, err := getNumber(e.element, ctx)
+
+	if err != nil {
+		return nil, NotExists, err
+	}
+
+	return -number, Number, nil
+}
+
+func (e negatedElement) stringValue(ctx *ASTContext) (string, error) {
+	v, _, err := e.value(ctx)
+
+	if err != nil {
+		return "", err
+	}
 
+	return numberText(v.(float64)), nil
 }
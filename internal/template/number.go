@@ -0,0 +1,153 @@
+package parser
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// numberFormat is the format numbers are written with in the output of templates.
+// When empty, numbers are written with the precision they need, so whole numbers have no decimals.
+var numberFormat string
+
+// SetNumberFormat sets the default format numbers are written with in the output of templates, as a
+// fmt format for a single float (such as "%.2f"). Returns an error if the format is not a valid one.
+func SetNumberFormat(format string) error {
+	if format != "" {
+		if formatted := fmt.Sprintf(format, 1.5); strings.Contains(formatted, "%!") {
+			return fmt.Errorf("Invalid number format %s", format)
+		}
+	}
+
+	numberFormat = format
+	return nil
+}
+
+// numberText converts a number into the text written in the output of templates, with the default format
+func numberText(fl float64) string {
+	if numberFormat != "" {
+		return fmt.Sprintf(numberFormat, fl)
+	}
+
+	return strconv.FormatFloat(fl, 'f', -1, 64)
+}
+
+// formatVerb finds the verb of the first argument of a fmt format
+var formatVerb = regexp.MustCompile(`%[-+# 0]*[0-9]*(?:\.[0-9]*)?([a-zA-Z])`)
+
+// integerVerbs are the fmt verbs which only apply to integers
+const integerVerbs = "bcdoOxXU"
+
+// formatBuiltin formats a value with a fmt format, such as "%.1f" or "%05d" - format(value, format)
+func formatBuiltin(ctx *ASTContext, args []item) (any, ElementType, error) {
+	if err := checkArgs("format", args, 2, 2); err != nil {
+		return nil, NotExists, err
+	}
+
+	format, err := stringArg("format", args, 1)
+	if err != nil {
+		return nil, NotExists, err
+	}
+
+	value := args[0].value
+
+	switch args[0].elemType {
+	case Number:
+		// integer verbs print floats as errors, so whole numbers are given as integers
+		fl, _ := value.(float64)
+		verb := formatVerb.FindStringSubmatch(format)
+
+		if verb != nil && strings.Contains(integerVerbs, verb[1]) && fl == math.Trunc(fl) {
+			value = int64(fl)
+		}
+	case Array, Object, NotExists:
+		return nil, NotExists, fmt.Errorf("Argument 1 of format must be a string, number, bool or date")
+	}
+
+	formatted := fmt.Sprintf(format, value)
+
+	if strings.Contains(formatted, "%!") {
+		return nil, NotExists, fmt.Errorf("Invalid format %s for %s", format, valueText(args[0].value, args[0].elemType))
+	}
+
+	return formatted, String, nil
+}
+
+// roundBuiltin rounds a number to the given number of decimal places, or to a whole number - round(number, [places])
+func roundBuiltin(ctx *ASTContext, args []item) (any, ElementType, error) {
+	if err := checkArgs("round", args, 1, 2); err != nil {
+		return nil, NotExists, err
+	}
+
+	fl, isNumber := args[0].value.(float64)
+
+	if args[0].elemType != Number || !isNumber {
+		return nil, NotExists, fmt.Errorf("Argument 1 of round must be a number")
+	}
+
+	places := 0
+
+	if len(args) == 2 {
+		var err error
+		places, err = intArg("round", args, 1)
+
+		if err != nil {
+			return nil, NotExists, err
+		}
+	}
+
+	scale := math.Pow(10, float64(places))
+	return math.Round(fl*scale) / scale, Number, nil
+}
+
+// thousandsBuiltin writes a number with a separator (by default a comma) between each group of three
+// digits of its whole part - thousands(number, [separator])
+func thousandsBuiltin(ctx *ASTContext, args []item) (any, ElementType, error) {
+	if err := checkArgs("thousands", args, 1, 2); err != nil {
+		return nil, NotExists, err
+	}
+
+	fl, isNumber := args[0].value.(float64)
+
+	if args[0].elemType != Number || !isNumber {
+		return nil, NotExists, fmt.Errorf("Argument 1 of thousands must be a number")
+	}
+
+	separator := ","
+
+	if len(args) == 2 {
+		var err error
+		separator, err = stringArg("thousands", args, 1)
+
+		if err != nil {
+			return nil, NotExists, err
+		}
+	}
+
+	text := numberText(fl)
+	sign, digits := "", text
+
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+
+	whole, decimals, hasDecimals := strings.Cut(digits, ".")
+	sb := strings.Builder{}
+	sb.WriteString(sign)
+
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			sb.WriteString(separator)
+		}
+		sb.WriteRune(digit)
+	}
+
+	if hasDecimals {
+		sb.WriteRune('.')
+		sb.WriteString(decimals)
+	}
+
+	return sb.String(), String, nil
+}
@@ -9,6 +9,8 @@ import (
 	"io"
 	"math"
 	"os"
+	"path/filepath"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -26,12 +28,11 @@ func toAnySlice(v any) ([]any, bool) {
 }
 
 func findOperator(elems []any) mathOperator {
-	fmt.Println(elems)
 	for _, e := range elems {
 		b, isByte := e.([]byte)
 
 		if isByte {
-			operator := textToOperator(b[0])
+			operator := textToOperator(string(b))
 			if operator != invalid {
 				return operator
 			}
@@ -42,6 +43,52 @@ func findOperator(elems []any) mathOperator {
 
 }
 
+// includeTemplate parses the template in path, which is relative to the directory of the template being parsed.
+// Templates are parsed through the partials of the current parse, so each is parsed only once.
+func includeTemplate(c *current, path string) (node, error) {
+	parsing, _ := c.globalStore[partialsKey].(*partials)
+	current, _ := c.globalStore[templateKey].(string)
+
+	if parsing == nil {
+		return nil, errors.New("Includes are only supported in templates parsed with ParseTemplate")
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(current), path)
+	}
+
+	return parsing.parse(path)
+}
+
+// sequence links the nodes of a sequence (a node followed by the sequence after it), applying the trim
+// markers between them, and returns its first node
+func sequence(v any) (any, error) {
+	vals, ok := toAnySlice(v)
+	if !ok {
+		return nil, nil
+	} else {
+		left, _ := vals[0].(node)
+		right, _ := vals[1].(node)
+
+		// items whose rule gave an error may have no node, the sequence continues with what follows them
+		if left == nil {
+			return right, nil
+		}
+
+		if trimsBefore(right) {
+			trimEnd(left)
+		}
+
+		if _, trimAfter := left.trims(); trimAfter {
+			trimStart(right)
+		}
+
+		left.setNext(right)
+
+		return left, nil
+	}
+}
+
 func expressionProcessor(t, f any) (any, error) {
 
 	term := t.(element)
@@ -69,23 +116,23 @@ var g = &grammar{
 	rules: []*rule{
 		{
 			name: "Start",
-			pos:  position{line: 58, col: 1, offset: 875},
+			pos:  position{line: 103, col: 1, offset: 2045},
 			expr: &actionExpr{
-				pos: position{line: 58, col: 10, offset: 884},
+				pos: position{line: 103, col: 10, offset: 2054},
 				run: (*parser).callonStart1,
 				expr: &seqExpr{
-					pos: position{line: 58, col: 10, offset: 884},
+					pos: position{line: 103, col: 10, offset: 2054},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 58, col: 10, offset: 884},
+							pos:   position{line: 103, col: 10, offset: 2054},
 							label: "top",
 							expr: &ruleRefExpr{
-								pos:  position{line: 58, col: 14, offset: 888},
+								pos:  position{line: 103, col: 14, offset: 2058},
 								name: "Seq",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 58, col: 18, offset: 892},
+							pos:  position{line: 103, col: 18, offset: 2062},
 							name: "EOF",
 						},
 					},
@@ -94,52 +141,31 @@ var g = &grammar{
 		},
 		{
 			name: "Seq",
-			pos:  position{line: 64, col: 1, offset: 996},
+			pos:  position{line: 108, col: 1, offset: 2090},
 			expr: &actionExpr{
-				pos: position{line: 64, col: 8, offset: 1003},
+				pos: position{line: 108, col: 8, offset: 2097},
 				run: (*parser).callonSeq1,
 				expr: &labeledExpr{
-					pos:   position{line: 64, col: 8, offset: 1003},
+					pos:   position{line: 108, col: 8, offset: 2097},
 					label: "v",
 					expr: &choiceExpr{
-						pos: position{line: 64, col: 11, offset: 1006},
+						pos: position{line: 108, col: 11, offset: 2100},
 						alternatives: []any{
 							&seqExpr{
-								pos: position{line: 64, col: 11, offset: 1006},
+								pos: position{line: 108, col: 11, offset: 2100},
 								exprs: []any{
-									&choiceExpr{
-										pos: position{line: 64, col: 13, offset: 1008},
-										alternatives: []any{
-											&ruleRefExpr{
-												pos:  position{line: 64, col: 13, offset: 1008},
-												name: "IfElse",
-											},
-											&ruleRefExpr{
-												pos:  position{line: 64, col: 22, offset: 1017},
-												name: "If",
-											},
-											&ruleRefExpr{
-												pos:  position{line: 64, col: 27, offset: 1022},
-												name: "For",
-											},
-											&ruleRefExpr{
-												pos:  position{line: 64, col: 33, offset: 1028},
-												name: "TextBlock",
-											},
-											&ruleRefExpr{
-												pos:  position{line: 64, col: 45, offset: 1040},
-												name: "Accessor",
-											},
-										},
+									&ruleRefExpr{
+										pos:  position{line: 108, col: 11, offset: 2100},
+										name: "SeqItem",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 64, col: 56, offset: 1051},
+										pos:  position{line: 108, col: 19, offset: 2108},
 										name: "Seq",
 									},
 								},
 							},
 							&litMatcher{
-								pos:        position{line: 64, col: 62, offset: 1057},
+								pos:        position{line: 108, col: 25, offset: 2114},
 								val:        "",
 								ignoreCase: false,
 								want:       "\"\"",
@@ -150,85 +176,128 @@ var g = &grammar{
 			},
 		},
 		{
-			name: "TextBlock",
-			pos:  position{line: 78, col: 1, offset: 1253},
-			expr: &actionExpr{
-				pos: position{line: 78, col: 14, offset: 1266},
-				run: (*parser).callonTextBlock1,
-				expr: &labeledExpr{
-					pos:   position{line: 78, col: 14, offset: 1266},
-					label: "t",
-					expr: &ruleRefExpr{
-						pos:  position{line: 78, col: 16, offset: 1268},
-						name: "Text",
+			name: "SeqItem",
+			pos:  position{line: 112, col: 1, offset: 2143},
+			expr: &choiceExpr{
+				pos: position{line: 112, col: 12, offset: 2154},
+				alternatives: []any{
+					&ruleRefExpr{
+						pos:  position{line: 112, col: 12, offset: 2154},
+						name: "Comment",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 112, col: 22, offset: 2164},
+						name: "Raw",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 112, col: 28, offset: 2170},
+						name: "IfElse",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 112, col: 37, offset: 2179},
+						name: "If",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 112, col: 42, offset: 2184},
+						name: "For",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 112, col: 48, offset: 2190},
+						name: "Switch",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 112, col: 57, offset: 2199},
+						name: "Let",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 112, col: 63, offset: 2205},
+						name: "Include",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 112, col: 73, offset: 2215},
+						name: "Define",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 112, col: 82, offset: 2224},
+						name: "Extends",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 112, col: 92, offset: 2234},
+						name: "Block",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 112, col: 100, offset: 2242},
+						name: "LoopControl",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 112, col: 114, offset: 2256},
+						name: "TextBlock",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 112, col: 126, offset: 2268},
+						name: "Accessor",
 					},
 				},
 			},
 		},
 		{
-			name: "Accessor",
-			pos:  position{line: 85, col: 1, offset: 1388},
+			name: "CaseSeq",
+			pos:  position{line: 115, col: 1, offset: 2378},
 			expr: &actionExpr{
-				pos: position{line: 85, col: 13, offset: 1400},
-				run: (*parser).callonAccessor1,
-				expr: &seqExpr{
-					pos: position{line: 85, col: 13, offset: 1400},
-					exprs: []any{
-						&ruleRefExpr{
-							pos:  position{line: 85, col: 13, offset: 1400},
-							name: "S",
-						},
-						&ruleRefExpr{
-							pos:  position{line: 85, col: 15, offset: 1402},
-							name: "_",
-						},
-						&labeledExpr{
-							pos:   position{line: 85, col: 18, offset: 1405},
-							label: "e",
-							expr: &ruleRefExpr{
-								pos:  position{line: 85, col: 20, offset: 1407},
-								name: "Element",
+				pos: position{line: 115, col: 12, offset: 2389},
+				run: (*parser).callonCaseSeq1,
+				expr: &labeledExpr{
+					pos:   position{line: 115, col: 12, offset: 2389},
+					label: "v",
+					expr: &choiceExpr{
+						pos: position{line: 115, col: 15, offset: 2392},
+						alternatives: []any{
+							&seqExpr{
+								pos: position{line: 115, col: 15, offset: 2392},
+								exprs: []any{
+									&ruleRefExpr{
+										pos:  position{line: 115, col: 15, offset: 2392},
+										name: "CaseItem",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 115, col: 24, offset: 2401},
+										name: "CaseSeq",
+									},
+								},
+							},
+							&litMatcher{
+								pos:        position{line: 115, col: 34, offset: 2411},
+								val:        "",
+								ignoreCase: false,
+								want:       "\"\"",
 							},
-						},
-						&ruleRefExpr{
-							pos:  position{line: 85, col: 28, offset: 1415},
-							name: "_",
-						},
-						&ruleRefExpr{
-							pos:  position{line: 85, col: 30, offset: 1417},
-							name: "S",
 						},
 					},
 				},
 			},
 		},
 		{
-			name: "Element",
-			pos:  position{line: 91, col: 1, offset: 1542},
+			name: "CaseItem",
+			pos:  position{line: 119, col: 1, offset: 2440},
 			expr: &actionExpr{
-				pos: position{line: 91, col: 12, offset: 1553},
-				run: (*parser).callonElement1,
-				expr: &labeledExpr{
-					pos:   position{line: 91, col: 12, offset: 1553},
-					label: "e",
-					expr: &choiceExpr{
-						pos: position{line: 91, col: 16, offset: 1557},
-						alternatives: []any{
-							&ruleRefExpr{
-								pos:  position{line: 91, col: 16, offset: 1557},
-								name: "Expression",
-							},
-							&ruleRefExpr{
-								pos:  position{line: 91, col: 29, offset: 1570},
-								name: "Constant",
-							},
-							&ruleRefExpr{
-								pos:  position{line: 91, col: 41, offset: 1582},
-								name: "UserFunction",
+				pos: position{line: 119, col: 13, offset: 2452},
+				run: (*parser).callonCaseItem1,
+				expr: &seqExpr{
+					pos: position{line: 119, col: 13, offset: 2452},
+					exprs: []any{
+						&notExpr{
+							pos: position{line: 119, col: 13, offset: 2452},
+							expr: &ruleRefExpr{
+								pos:  position{line: 119, col: 14, offset: 2453},
+								name: "CaseTag",
 							},
-							&ruleRefExpr{
-								pos:  position{line: 91, col: 56, offset: 1597},
-								name: "AccessElement",
+						},
+						&labeledExpr{
+							pos:   position{line: 119, col: 22, offset: 2461},
+							label: "i",
+							expr: &ruleRefExpr{
+								pos:  position{line: 119, col: 24, offset: 2463},
+								name: "SeqItem",
 							},
 						},
 					},
@@ -236,344 +305,461 @@ var g = &grammar{
 			},
 		},
 		{
-			name: "NonMathElement",
-			pos:  position{line: 95, col: 1, offset: 1639},
-			expr: &actionExpr{
-				pos: position{line: 95, col: 19, offset: 1657},
-				run: (*parser).callonNonMathElement1,
-				expr: &labeledExpr{
-					pos:   position{line: 95, col: 19, offset: 1657},
-					label: "e",
-					expr: &choiceExpr{
-						pos: position{line: 95, col: 22, offset: 1660},
+			name: "CaseTag",
+			pos:  position{line: 123, col: 1, offset: 2491},
+			expr: &seqExpr{
+				pos: position{line: 123, col: 12, offset: 2502},
+				exprs: []any{
+					&ruleRefExpr{
+						pos:  position{line: 123, col: 12, offset: 2502},
+						name: "Open",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 123, col: 17, offset: 2507},
+						name: "_",
+					},
+					&choiceExpr{
+						pos: position{line: 123, col: 20, offset: 2510},
 						alternatives: []any{
-							&ruleRefExpr{
-								pos:  position{line: 95, col: 22, offset: 1660},
-								name: "Constant",
-							},
-							&ruleRefExpr{
-								pos:  position{line: 95, col: 33, offset: 1671},
-								name: "UserFunction",
+							&litMatcher{
+								pos:        position{line: 123, col: 20, offset: 2510},
+								val:        "case",
+								ignoreCase: false,
+								want:       "\"case\"",
 							},
-							&ruleRefExpr{
-								pos:  position{line: 95, col: 48, offset: 1686},
-								name: "AccessElement",
+							&litMatcher{
+								pos:        position{line: 123, col: 29, offset: 2519},
+								val:        "default",
+								ignoreCase: false,
+								want:       "\"default\"",
 							},
 						},
 					},
+					&notExpr{
+						pos: position{line: 123, col: 40, offset: 2530},
+						expr: &charClassMatcher{
+							pos:        position{line: 123, col: 41, offset: 2531},
+							val:        "[a-zA-Z0-9]",
+							ranges:     []rune{'a', 'z', 'A', 'Z', '0', '9'},
+							ignoreCase: false,
+							inverted:   false,
+						},
+					},
 				},
 			},
 		},
 		{
-			name: "Expression",
-			pos:  position{line: 99, col: 1, offset: 1727},
+			name: "Comment",
+			pos:  position{line: 126, col: 1, offset: 2632},
 			expr: &actionExpr{
-				pos: position{line: 99, col: 15, offset: 1741},
-				run: (*parser).callonExpression1,
+				pos: position{line: 126, col: 12, offset: 2643},
+				run: (*parser).callonComment1,
 				expr: &seqExpr{
-					pos: position{line: 99, col: 15, offset: 1741},
+					pos: position{line: 126, col: 12, offset: 2643},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 99, col: 15, offset: 1741},
-							label: "t",
+							pos:   position{line: 126, col: 12, offset: 2643},
+							label: "lo",
 							expr: &ruleRefExpr{
-								pos:  position{line: 99, col: 17, offset: 1743},
-								name: "Term",
+								pos:  position{line: 126, col: 15, offset: 2646},
+								name: "Open",
 							},
 						},
-						&ruleRefExpr{
-							pos:  position{line: 99, col: 22, offset: 1748},
-							name: "_",
+						&litMatcher{
+							pos:        position{line: 126, col: 20, offset: 2651},
+							val:        "#",
+							ignoreCase: false,
+							want:       "\"#\"",
 						},
-						&labeledExpr{
-							pos:   position{line: 99, col: 24, offset: 1750},
-							label: "f",
-							expr: &zeroOrMoreExpr{
-								pos: position{line: 99, col: 26, offset: 1752},
-								expr: &choiceExpr{
-									pos: position{line: 99, col: 27, offset: 1753},
-									alternatives: []any{
-										&ruleRefExpr{
-											pos:  position{line: 99, col: 27, offset: 1753},
-											name: "Addition",
-										},
-										&ruleRefExpr{
-											pos:  position{line: 99, col: 38, offset: 1764},
-											name: "Subtraction",
+						&zeroOrMoreExpr{
+							pos: position{line: 126, col: 24, offset: 2655},
+							expr: &seqExpr{
+								pos: position{line: 126, col: 25, offset: 2656},
+								exprs: []any{
+									&notExpr{
+										pos: position{line: 126, col: 25, offset: 2656},
+										expr: &seqExpr{
+											pos: position{line: 126, col: 27, offset: 2658},
+											exprs: []any{
+												&litMatcher{
+													pos:        position{line: 126, col: 27, offset: 2658},
+													val:        "#",
+													ignoreCase: false,
+													want:       "\"#\"",
+												},
+												&ruleRefExpr{
+													pos:  position{line: 126, col: 31, offset: 2662},
+													name: "Close",
+												},
+											},
 										},
 									},
+									&anyMatcher{
+										line: 126, col: 38, offset: 2669,
+									},
 								},
 							},
 						},
+						&litMatcher{
+							pos:        position{line: 126, col: 42, offset: 2673},
+							val:        "#",
+							ignoreCase: false,
+							want:       "\"#\"",
+						},
+						&labeledExpr{
+							pos:   position{line: 126, col: 46, offset: 2677},
+							label: "lc",
+							expr: &ruleRefExpr{
+								pos:  position{line: 126, col: 49, offset: 2680},
+								name: "Close",
+							},
+						},
 					},
 				},
 			},
 		},
 		{
-			name: "Term",
-			pos:  position{line: 106, col: 1, offset: 1881},
+			name: "Raw",
+			pos:  position{line: 133, col: 1, offset: 2891},
 			expr: &actionExpr{
-				pos: position{line: 106, col: 9, offset: 1889},
-				run: (*parser).callonTerm1,
+				pos: position{line: 133, col: 8, offset: 2898},
+				run: (*parser).callonRaw1,
 				expr: &seqExpr{
-					pos: position{line: 106, col: 9, offset: 1889},
+					pos: position{line: 133, col: 8, offset: 2898},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 106, col: 9, offset: 1889},
-							label: "t",
+							pos:   position{line: 133, col: 8, offset: 2898},
+							label: "lo",
 							expr: &ruleRefExpr{
-								pos:  position{line: 106, col: 11, offset: 1891},
-								name: "Factor",
+								pos:  position{line: 133, col: 11, offset: 2901},
+								name: "Open",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 106, col: 18, offset: 1898},
+							pos:  position{line: 133, col: 16, offset: 2906},
 							name: "_",
 						},
-						&labeledExpr{
-							pos:   position{line: 106, col: 20, offset: 1900},
-							label: "f",
-							expr: &zeroOrMoreExpr{
-								pos: position{line: 106, col: 22, offset: 1902},
-								expr: &choiceExpr{
-									pos: position{line: 106, col: 23, offset: 1903},
-									alternatives: []any{
-										&ruleRefExpr{
-											pos:  position{line: 106, col: 23, offset: 1903},
-											name: "Multiplication",
-										},
-										&ruleRefExpr{
-											pos:  position{line: 106, col: 40, offset: 1920},
-											name: "Division",
-										},
-									},
-								},
-							},
-						},
-					},
-				},
-			},
-		},
-		{
-			name: "Factor",
-			pos:  position{line: 110, col: 1, offset: 1974},
-			expr: &choiceExpr{
-				pos: position{line: 110, col: 11, offset: 1984},
-				alternatives: []any{
-					&ruleRefExpr{
-						pos:  position{line: 110, col: 11, offset: 1984},
-						name: "NonMathElement",
-					},
-					&ruleRefExpr{
-						pos:  position{line: 110, col: 28, offset: 2001},
-						name: "GroupedExpression",
-					},
-				},
-			},
-		},
-		{
-			name: "GroupedExpression",
-			pos:  position{line: 112, col: 1, offset: 2022},
-			expr: &actionExpr{
-				pos: position{line: 112, col: 22, offset: 2043},
-				run: (*parser).callonGroupedExpression1,
-				expr: &seqExpr{
-					pos: position{line: 112, col: 22, offset: 2043},
-					exprs: []any{
 						&litMatcher{
-							pos:        position{line: 112, col: 22, offset: 2043},
-							val:        "(",
+							pos:        position{line: 133, col: 18, offset: 2908},
+							val:        "raw",
 							ignoreCase: false,
-							want:       "\"(\"",
+							want:       "\"raw\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 112, col: 26, offset: 2047},
+							pos:  position{line: 133, col: 24, offset: 2914},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 112, col: 29, offset: 2050},
-							label: "e",
+							pos:   position{line: 133, col: 26, offset: 2916},
+							label: "ic",
 							expr: &ruleRefExpr{
-								pos:  position{line: 112, col: 31, offset: 2052},
-								name: "Expression",
+								pos:  position{line: 133, col: 29, offset: 2919},
+								name: "Close",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 133, col: 35, offset: 2925},
+							label: "t",
+							expr: &ruleRefExpr{
+								pos:  position{line: 133, col: 37, offset: 2927},
+								name: "RawText",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 133, col: 45, offset: 2935},
+							label: "eo",
+							expr: &ruleRefExpr{
+								pos:  position{line: 133, col: 48, offset: 2938},
+								name: "Open",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 112, col: 42, offset: 2063},
+							pos:  position{line: 133, col: 53, offset: 2943},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 112, col: 44, offset: 2065},
-							val:        ")",
+							pos:        position{line: 133, col: 55, offset: 2945},
+							val:        "endraw",
 							ignoreCase: false,
-							want:       "\")\"",
+							want:       "\"endraw\"",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 133, col: 64, offset: 2954},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 133, col: 66, offset: 2956},
+							label: "lc",
+							expr: &ruleRefExpr{
+								pos:  position{line: 133, col: 69, offset: 2959},
+								name: "Close",
+							},
 						},
 					},
 				},
 			},
 		},
 		{
-			name: "Addition",
-			pos:  position{line: 116, col: 1, offset: 2095},
-			expr: &seqExpr{
-				pos: position{line: 116, col: 13, offset: 2107},
-				exprs: []any{
-					&ruleRefExpr{
-						pos:  position{line: 116, col: 13, offset: 2107},
-						name: "_",
-					},
-					&litMatcher{
-						pos:        position{line: 116, col: 15, offset: 2109},
-						val:        "+",
-						ignoreCase: false,
-						want:       "\"+\"",
-					},
-					&ruleRefExpr{
-						pos:  position{line: 116, col: 19, offset: 2113},
-						name: "_",
-					},
-					&ruleRefExpr{
-						pos:  position{line: 116, col: 21, offset: 2115},
-						name: "Term",
+			name: "RawText",
+			pos:  position{line: 148, col: 1, offset: 3169},
+			expr: &actionExpr{
+				pos: position{line: 148, col: 12, offset: 3180},
+				run: (*parser).callonRawText1,
+				expr: &zeroOrMoreExpr{
+					pos: position{line: 148, col: 12, offset: 3180},
+					expr: &seqExpr{
+						pos: position{line: 148, col: 13, offset: 3181},
+						exprs: []any{
+							&notExpr{
+								pos: position{line: 148, col: 13, offset: 3181},
+								expr: &seqExpr{
+									pos: position{line: 148, col: 15, offset: 3183},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 148, col: 15, offset: 3183},
+											name: "Open",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 148, col: 20, offset: 3188},
+											name: "_",
+										},
+										&litMatcher{
+											pos:        position{line: 148, col: 22, offset: 3190},
+											val:        "endraw",
+											ignoreCase: false,
+											want:       "\"endraw\"",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 148, col: 31, offset: 3199},
+											name: "_",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 148, col: 33, offset: 3201},
+											name: "Close",
+										},
+									},
+								},
+							},
+							&anyMatcher{
+								line: 148, col: 40, offset: 3208,
+							},
+						},
 					},
 				},
 			},
 		},
 		{
-			name: "Subtraction",
-			pos:  position{line: 118, col: 1, offset: 2123},
-			expr: &seqExpr{
-				pos: position{line: 118, col: 16, offset: 2138},
-				exprs: []any{
-					&ruleRefExpr{
-						pos:  position{line: 118, col: 16, offset: 2138},
-						name: "_",
-					},
-					&litMatcher{
-						pos:        position{line: 118, col: 18, offset: 2140},
-						val:        "-",
-						ignoreCase: false,
-						want:       "\"-\"",
-					},
-					&ruleRefExpr{
-						pos:  position{line: 118, col: 22, offset: 2144},
-						name: "_",
-					},
-					&ruleRefExpr{
-						pos:  position{line: 118, col: 24, offset: 2146},
-						name: "Term",
+			name: "TextBlock",
+			pos:  position{line: 152, col: 1, offset: 3245},
+			expr: &actionExpr{
+				pos: position{line: 152, col: 14, offset: 3258},
+				run: (*parser).callonTextBlock1,
+				expr: &labeledExpr{
+					pos:   position{line: 152, col: 14, offset: 3258},
+					label: "t",
+					expr: &ruleRefExpr{
+						pos:  position{line: 152, col: 16, offset: 3260},
+						name: "Text",
 					},
 				},
 			},
 		},
 		{
-			name: "Multiplication",
-			pos:  position{line: 120, col: 1, offset: 2154},
-			expr: &seqExpr{
-				pos: position{line: 120, col: 19, offset: 2172},
-				exprs: []any{
-					&ruleRefExpr{
-						pos:  position{line: 120, col: 19, offset: 2172},
-						name: "_",
-					},
-					&litMatcher{
-						pos:        position{line: 120, col: 21, offset: 2174},
-						val:        "*",
-						ignoreCase: false,
-						want:       "\"*\"",
-					},
-					&ruleRefExpr{
-						pos:  position{line: 120, col: 25, offset: 2178},
-						name: "_",
-					},
-					&ruleRefExpr{
-						pos:  position{line: 120, col: 27, offset: 2180},
-						name: "Factor",
+			name: "Accessor",
+			pos:  position{line: 161, col: 1, offset: 3514},
+			expr: &actionExpr{
+				pos: position{line: 161, col: 13, offset: 3526},
+				run: (*parser).callonAccessor1,
+				expr: &seqExpr{
+					pos: position{line: 161, col: 13, offset: 3526},
+					exprs: []any{
+						&labeledExpr{
+							pos:   position{line: 161, col: 13, offset: 3526},
+							label: "lo",
+							expr: &ruleRefExpr{
+								pos:  position{line: 161, col: 16, offset: 3529},
+								name: "Open",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 161, col: 21, offset: 3534},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 161, col: 24, offset: 3537},
+							label: "e",
+							expr: &ruleRefExpr{
+								pos:  position{line: 161, col: 26, offset: 3539},
+								name: "Element",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 161, col: 34, offset: 3547},
+							label: "ps",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 161, col: 37, offset: 3550},
+								expr: &seqExpr{
+									pos: position{line: 161, col: 38, offset: 3551},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 161, col: 38, offset: 3551},
+											name: "_",
+										},
+										&litMatcher{
+											pos:        position{line: 161, col: 40, offset: 3553},
+											val:        "|",
+											ignoreCase: false,
+											want:       "\"|\"",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 161, col: 44, offset: 3557},
+											name: "_",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 161, col: 46, offset: 3559},
+											name: "PipeStage",
+										},
+									},
+								},
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 161, col: 58, offset: 3571},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 161, col: 60, offset: 3573},
+							label: "lc",
+							expr: &ruleRefExpr{
+								pos:  position{line: 161, col: 63, offset: 3576},
+								name: "Close",
+							},
+						},
 					},
 				},
 			},
 		},
 		{
-			name: "Division",
-			pos:  position{line: 122, col: 1, offset: 2190},
-			expr: &seqExpr{
-				pos: position{line: 122, col: 13, offset: 2202},
-				exprs: []any{
-					&ruleRefExpr{
-						pos:  position{line: 122, col: 13, offset: 2202},
-						name: "_",
-					},
-					&litMatcher{
-						pos:        position{line: 122, col: 15, offset: 2204},
-						val:        "/",
-						ignoreCase: false,
-						want:       "\"/\"",
-					},
-					&ruleRefExpr{
-						pos:  position{line: 122, col: 19, offset: 2208},
-						name: "_",
-					},
-					&ruleRefExpr{
-						pos:  position{line: 122, col: 21, offset: 2210},
-						name: "Factor",
+			name: "Element",
+			pos:  position{line: 179, col: 1, offset: 4217},
+			expr: &actionExpr{
+				pos: position{line: 179, col: 12, offset: 4228},
+				run: (*parser).callonElement1,
+				expr: &seqExpr{
+					pos: position{line: 179, col: 12, offset: 4228},
+					exprs: []any{
+						&labeledExpr{
+							pos:   position{line: 179, col: 12, offset: 4228},
+							label: "cond",
+							expr: &ruleRefExpr{
+								pos:  position{line: 179, col: 17, offset: 4233},
+								name: "OrCondition",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 179, col: 29, offset: 4245},
+							label: "t",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 179, col: 31, offset: 4247},
+								expr: &seqExpr{
+									pos: position{line: 179, col: 32, offset: 4248},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 179, col: 32, offset: 4248},
+											name: "_",
+										},
+										&litMatcher{
+											pos:        position{line: 179, col: 34, offset: 4250},
+											val:        "?",
+											ignoreCase: false,
+											want:       "\"?\"",
+										},
+										&notExpr{
+											pos: position{line: 179, col: 38, offset: 4254},
+											expr: &litMatcher{
+												pos:        position{line: 179, col: 39, offset: 4255},
+												val:        "?",
+												ignoreCase: false,
+												want:       "\"?\"",
+											},
+										},
+										&ruleRefExpr{
+											pos:  position{line: 179, col: 43, offset: 4259},
+											name: "_",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 179, col: 45, offset: 4261},
+											name: "Element",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 179, col: 53, offset: 4269},
+											name: "_",
+										},
+										&litMatcher{
+											pos:        position{line: 179, col: 55, offset: 4271},
+											val:        ":",
+											ignoreCase: false,
+											want:       "\":\"",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 179, col: 59, offset: 4275},
+											name: "_",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 179, col: 61, offset: 4277},
+											name: "Element",
+										},
+									},
+								},
+							},
+						},
 					},
 				},
 			},
 		},
 		{
-			name: "AccessElement",
-			pos:  position{line: 127, col: 1, offset: 2226},
+			name: "Coalesce",
+			pos:  position{line: 195, col: 1, offset: 4949},
 			expr: &actionExpr{
-				pos: position{line: 127, col: 18, offset: 2243},
-				run: (*parser).callonAccessElement1,
+				pos: position{line: 195, col: 13, offset: 4961},
+				run: (*parser).callonCoalesce1,
 				expr: &seqExpr{
-					pos: position{line: 127, col: 18, offset: 2243},
+					pos: position{line: 195, col: 13, offset: 4961},
 					exprs: []any{
-						&notExpr{
-							pos: position{line: 127, col: 18, offset: 2243},
-							expr: &choiceExpr{
-								pos: position{line: 127, col: 20, offset: 2245},
-								alternatives: []any{
-									&ruleRefExpr{
-										pos:  position{line: 127, col: 20, offset: 2245},
-										name: "Special",
-									},
-									&ruleRefExpr{
-										pos:  position{line: 127, col: 30, offset: 2255},
-										name: "S",
-									},
-								},
+						&labeledExpr{
+							pos:   position{line: 195, col: 13, offset: 4961},
+							label: "first",
+							expr: &ruleRefExpr{
+								pos:  position{line: 195, col: 19, offset: 4967},
+								name: "Operand",
 							},
 						},
-						&oneOrMoreExpr{
-							pos: position{line: 127, col: 33, offset: 2258},
-							expr: &choiceExpr{
-								pos: position{line: 127, col: 34, offset: 2259},
-								alternatives: []any{
-									&charClassMatcher{
-										pos:        position{line: 127, col: 34, offset: 2259},
-										val:        "[a-zA-Z0-9]",
-										ranges:     []rune{'a', 'z', 'A', 'Z', '0', '9'},
-										ignoreCase: false,
-										inverted:   false,
-									},
-									&litMatcher{
-										pos:        position{line: 127, col: 48, offset: 2273},
-										val:        "->",
-										ignoreCase: false,
-										want:       "\"->\"",
-									},
-									&litMatcher{
-										pos:        position{line: 127, col: 55, offset: 2280},
-										val:        "[",
-										ignoreCase: false,
-										want:       "\"[\"",
-									},
-									&litMatcher{
-										pos:        position{line: 127, col: 61, offset: 2286},
-										val:        "]",
-										ignoreCase: false,
-										want:       "\"]\"",
+						&labeledExpr{
+							pos:   position{line: 195, col: 27, offset: 4975},
+							label: "rest",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 195, col: 32, offset: 4980},
+								expr: &seqExpr{
+									pos: position{line: 195, col: 33, offset: 4981},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 195, col: 33, offset: 4981},
+											name: "_",
+										},
+										&litMatcher{
+											pos:        position{line: 195, col: 35, offset: 4983},
+											val:        "??",
+											ignoreCase: false,
+											want:       "\"??\"",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 195, col: 40, offset: 4988},
+											name: "_",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 195, col: 42, offset: 4990},
+											name: "Operand",
+										},
 									},
 								},
 							},
@@ -583,496 +769,2172 @@ var g = &grammar{
 			},
 		},
 		{
-			name: "IfElse",
-			pos:  position{line: 133, col: 1, offset: 2370},
+			name: "Operand",
+			pos:  position{line: 207, col: 1, offset: 5242},
+			expr: &actionExpr{
+				pos: position{line: 207, col: 12, offset: 5253},
+				run: (*parser).callonOperand1,
+				expr: &labeledExpr{
+					pos:   position{line: 207, col: 12, offset: 5253},
+					label: "e",
+					expr: &choiceExpr{
+						pos: position{line: 207, col: 16, offset: 5257},
+						alternatives: []any{
+							&ruleRefExpr{
+								pos:  position{line: 207, col: 16, offset: 5257},
+								name: "Concatenation",
+							},
+							&ruleRefExpr{
+								pos:  position{line: 207, col: 32, offset: 5273},
+								name: "Constant",
+							},
+							&ruleRefExpr{
+								pos:  position{line: 207, col: 44, offset: 5285},
+								name: "UserFunction",
+							},
+							&ruleRefExpr{
+								pos:  position{line: 207, col: 59, offset: 5300},
+								name: "AccessElement",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "NonMathElement",
+			pos:  position{line: 211, col: 1, offset: 5338},
+			expr: &actionExpr{
+				pos: position{line: 211, col: 19, offset: 5356},
+				run: (*parser).callonNonMathElement1,
+				expr: &labeledExpr{
+					pos:   position{line: 211, col: 19, offset: 5356},
+					label: "e",
+					expr: &choiceExpr{
+						pos: position{line: 211, col: 22, offset: 5359},
+						alternatives: []any{
+							&ruleRefExpr{
+								pos:  position{line: 211, col: 22, offset: 5359},
+								name: "Constant",
+							},
+							&ruleRefExpr{
+								pos:  position{line: 211, col: 33, offset: 5370},
+								name: "UserFunction",
+							},
+							&ruleRefExpr{
+								pos:  position{line: 211, col: 48, offset: 5385},
+								name: "AccessElement",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Concatenation",
+			pos:  position{line: 216, col: 1, offset: 5493},
+			expr: &actionExpr{
+				pos: position{line: 216, col: 18, offset: 5510},
+				run: (*parser).callonConcatenation1,
+				expr: &seqExpr{
+					pos: position{line: 216, col: 18, offset: 5510},
+					exprs: []any{
+						&labeledExpr{
+							pos:   position{line: 216, col: 18, offset: 5510},
+							label: "first",
+							expr: &ruleRefExpr{
+								pos:  position{line: 216, col: 24, offset: 5516},
+								name: "Expression",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 216, col: 35, offset: 5527},
+							label: "rest",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 216, col: 40, offset: 5532},
+								expr: &seqExpr{
+									pos: position{line: 216, col: 41, offset: 5533},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 216, col: 41, offset: 5533},
+											name: "_",
+										},
+										&litMatcher{
+											pos:        position{line: 216, col: 43, offset: 5535},
+											val:        "~",
+											ignoreCase: false,
+											want:       "\"~\"",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 216, col: 47, offset: 5539},
+											name: "_",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 216, col: 49, offset: 5541},
+											name: "Expression",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Expression",
+			pos:  position{line: 233, col: 1, offset: 5835},
+			expr: &actionExpr{
+				pos: position{line: 233, col: 15, offset: 5849},
+				run: (*parser).callonExpression1,
+				expr: &seqExpr{
+					pos: position{line: 233, col: 15, offset: 5849},
+					exprs: []any{
+						&labeledExpr{
+							pos:   position{line: 233, col: 15, offset: 5849},
+							label: "t",
+							expr: &ruleRefExpr{
+								pos:  position{line: 233, col: 17, offset: 5851},
+								name: "Term",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 233, col: 22, offset: 5856},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 233, col: 24, offset: 5858},
+							label: "f",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 233, col: 26, offset: 5860},
+								expr: &choiceExpr{
+									pos: position{line: 233, col: 27, offset: 5861},
+									alternatives: []any{
+										&ruleRefExpr{
+											pos:  position{line: 233, col: 27, offset: 5861},
+											name: "Addition",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 233, col: 38, offset: 5872},
+											name: "Subtraction",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Term",
+			pos:  position{line: 240, col: 1, offset: 5982},
+			expr: &actionExpr{
+				pos: position{line: 240, col: 9, offset: 5990},
+				run: (*parser).callonTerm1,
+				expr: &seqExpr{
+					pos: position{line: 240, col: 9, offset: 5990},
+					exprs: []any{
+						&labeledExpr{
+							pos:   position{line: 240, col: 9, offset: 5990},
+							label: "t",
+							expr: &ruleRefExpr{
+								pos:  position{line: 240, col: 11, offset: 5992},
+								name: "Unary",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 240, col: 17, offset: 5998},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 240, col: 19, offset: 6000},
+							label: "f",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 240, col: 21, offset: 6002},
+								expr: &choiceExpr{
+									pos: position{line: 240, col: 22, offset: 6003},
+									alternatives: []any{
+										&ruleRefExpr{
+											pos:  position{line: 240, col: 22, offset: 6003},
+											name: "Multiplication",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 240, col: 39, offset: 6020},
+											name: "IntegerDivision",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 240, col: 57, offset: 6038},
+											name: "Division",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 240, col: 68, offset: 6049},
+											name: "Modulo",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Unary",
+			pos:  position{line: 245, col: 1, offset: 6159},
+			expr: &choiceExpr{
+				pos: position{line: 245, col: 10, offset: 6168},
+				alternatives: []any{
+					&actionExpr{
+						pos: position{line: 245, col: 10, offset: 6168},
+						run: (*parser).callonUnary2,
+						expr: &seqExpr{
+							pos: position{line: 245, col: 10, offset: 6168},
+							exprs: []any{
+								&litMatcher{
+									pos:        position{line: 245, col: 10, offset: 6168},
+									val:        "-",
+									ignoreCase: false,
+									want:       "\"-\"",
+								},
+								&ruleRefExpr{
+									pos:  position{line: 245, col: 14, offset: 6172},
+									name: "_",
+								},
+								&labeledExpr{
+									pos:   position{line: 245, col: 16, offset: 6174},
+									label: "u",
+									expr: &ruleRefExpr{
+										pos:  position{line: 245, col: 18, offset: 6176},
+										name: "Unary",
+									},
+								},
+							},
+						},
+					},
+					&ruleRefExpr{
+						pos:  position{line: 247, col: 5, offset: 6238},
+						name: "Power",
+					},
+				},
+			},
+		},
+		{
+			name: "Power",
+			pos:  position{line: 250, col: 1, offset: 6304},
+			expr: &actionExpr{
+				pos: position{line: 250, col: 10, offset: 6313},
+				run: (*parser).callonPower1,
+				expr: &seqExpr{
+					pos: position{line: 250, col: 10, offset: 6313},
+					exprs: []any{
+						&labeledExpr{
+							pos:   position{line: 250, col: 10, offset: 6313},
+							label: "b",
+							expr: &ruleRefExpr{
+								pos:  position{line: 250, col: 12, offset: 6315},
+								name: "Factor",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 250, col: 19, offset: 6322},
+							label: "e",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 250, col: 21, offset: 6324},
+								expr: &seqExpr{
+									pos: position{line: 250, col: 22, offset: 6325},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 250, col: 22, offset: 6325},
+											name: "_",
+										},
+										&litMatcher{
+											pos:        position{line: 250, col: 24, offset: 6327},
+											val:        "^",
+											ignoreCase: false,
+											want:       "\"^\"",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 250, col: 28, offset: 6331},
+											name: "_",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 250, col: 30, offset: 6333},
+											name: "Unary",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Factor",
+			pos:  position{line: 260, col: 1, offset: 6510},
+			expr: &choiceExpr{
+				pos: position{line: 260, col: 11, offset: 6520},
+				alternatives: []any{
+					&ruleRefExpr{
+						pos:  position{line: 260, col: 11, offset: 6520},
+						name: "NonMathElement",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 260, col: 28, offset: 6537},
+						name: "GroupedExpression",
+					},
+				},
+			},
+		},
+		{
+			name: "GroupedExpression",
+			pos:  position{line: 262, col: 1, offset: 6556},
+			expr: &actionExpr{
+				pos: position{line: 262, col: 22, offset: 6577},
+				run: (*parser).callonGroupedExpression1,
+				expr: &seqExpr{
+					pos: position{line: 262, col: 22, offset: 6577},
+					exprs: []any{
+						&litMatcher{
+							pos:        position{line: 262, col: 22, offset: 6577},
+							val:        "(",
+							ignoreCase: false,
+							want:       "\"(\"",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 262, col: 26, offset: 6581},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 262, col: 29, offset: 6584},
+							label: "e",
+							expr: &ruleRefExpr{
+								pos:  position{line: 262, col: 31, offset: 6586},
+								name: "Expression",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 262, col: 42, offset: 6597},
+							name: "_",
+						},
+						&litMatcher{
+							pos:        position{line: 262, col: 44, offset: 6599},
+							val:        ")",
+							ignoreCase: false,
+							want:       "\")\"",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Addition",
+			pos:  position{line: 266, col: 1, offset: 6625},
+			expr: &seqExpr{
+				pos: position{line: 266, col: 13, offset: 6637},
+				exprs: []any{
+					&ruleRefExpr{
+						pos:  position{line: 266, col: 13, offset: 6637},
+						name: "_",
+					},
+					&litMatcher{
+						pos:        position{line: 266, col: 15, offset: 6639},
+						val:        "+",
+						ignoreCase: false,
+						want:       "\"+\"",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 266, col: 19, offset: 6643},
+						name: "_",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 266, col: 21, offset: 6645},
+						name: "Term",
+					},
+				},
+			},
+		},
+		{
+			name: "Subtraction",
+			pos:  position{line: 268, col: 1, offset: 6651},
+			expr: &seqExpr{
+				pos: position{line: 268, col: 16, offset: 6666},
+				exprs: []any{
+					&ruleRefExpr{
+						pos:  position{line: 268, col: 16, offset: 6666},
+						name: "_",
+					},
+					&litMatcher{
+						pos:        position{line: 268, col: 18, offset: 6668},
+						val:        "-",
+						ignoreCase: false,
+						want:       "\"-\"",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 268, col: 22, offset: 6672},
+						name: "_",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 268, col: 24, offset: 6674},
+						name: "Term",
+					},
+				},
+			},
+		},
+		{
+			name: "Multiplication",
+			pos:  position{line: 270, col: 1, offset: 6680},
+			expr: &seqExpr{
+				pos: position{line: 270, col: 19, offset: 6698},
+				exprs: []any{
+					&ruleRefExpr{
+						pos:  position{line: 270, col: 19, offset: 6698},
+						name: "_",
+					},
+					&litMatcher{
+						pos:        position{line: 270, col: 21, offset: 6700},
+						val:        "*",
+						ignoreCase: false,
+						want:       "\"*\"",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 270, col: 25, offset: 6704},
+						name: "_",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 270, col: 27, offset: 6706},
+						name: "Unary",
+					},
+				},
+			},
+		},
+		{
+			name: "IntegerDivision",
+			pos:  position{line: 272, col: 1, offset: 6713},
+			expr: &seqExpr{
+				pos: position{line: 272, col: 20, offset: 6732},
+				exprs: []any{
+					&ruleRefExpr{
+						pos:  position{line: 272, col: 20, offset: 6732},
+						name: "_",
+					},
+					&litMatcher{
+						pos:        position{line: 272, col: 22, offset: 6734},
+						val:        "//",
+						ignoreCase: false,
+						want:       "\"//\"",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 272, col: 27, offset: 6739},
+						name: "_",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 272, col: 29, offset: 6741},
+						name: "Unary",
+					},
+				},
+			},
+		},
+		{
+			name: "Division",
+			pos:  position{line: 274, col: 1, offset: 6748},
+			expr: &seqExpr{
+				pos: position{line: 274, col: 13, offset: 6760},
+				exprs: []any{
+					&ruleRefExpr{
+						pos:  position{line: 274, col: 13, offset: 6760},
+						name: "_",
+					},
+					&litMatcher{
+						pos:        position{line: 274, col: 15, offset: 6762},
+						val:        "/",
+						ignoreCase: false,
+						want:       "\"/\"",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 274, col: 19, offset: 6766},
+						name: "_",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 274, col: 21, offset: 6768},
+						name: "Unary",
+					},
+				},
+			},
+		},
+		{
+			name: "Modulo",
+			pos:  position{line: 276, col: 1, offset: 6775},
+			expr: &seqExpr{
+				pos: position{line: 276, col: 11, offset: 6785},
+				exprs: []any{
+					&ruleRefExpr{
+						pos:  position{line: 276, col: 11, offset: 6785},
+						name: "_",
+					},
+					&litMatcher{
+						pos:        position{line: 276, col: 13, offset: 6787},
+						val:        "%",
+						ignoreCase: false,
+						want:       "\"%\"",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 276, col: 17, offset: 6791},
+						name: "_",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 276, col: 19, offset: 6793},
+						name: "Unary",
+					},
+				},
+			},
+		},
+		{
+			name: "AccessElement",
+			pos:  position{line: 281, col: 1, offset: 6803},
+			expr: &actionExpr{
+				pos: position{line: 281, col: 18, offset: 6820},
+				run: (*parser).callonAccessElement1,
+				expr: &seqExpr{
+					pos: position{line: 281, col: 18, offset: 6820},
+					exprs: []any{
+						&notExpr{
+							pos: position{line: 281, col: 18, offset: 6820},
+							expr: &choiceExpr{
+								pos: position{line: 281, col: 20, offset: 6822},
+								alternatives: []any{
+									&ruleRefExpr{
+										pos:  position{line: 281, col: 20, offset: 6822},
+										name: "Special",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 281, col: 30, offset: 6832},
+										name: "S",
+									},
+								},
+							},
+						},
+						&oneOrMoreExpr{
+							pos: position{line: 281, col: 33, offset: 6835},
+							expr: &choiceExpr{
+								pos: position{line: 281, col: 34, offset: 6836},
+								alternatives: []any{
+									&charClassMatcher{
+										pos:        position{line: 281, col: 34, offset: 6836},
+										val:        "[a-zA-Z0-9]",
+										ranges:     []rune{'a', 'z', 'A', 'Z', '0', '9'},
+										ignoreCase: false,
+										inverted:   false,
+									},
+									&litMatcher{
+										pos:        position{line: 281, col: 48, offset: 6850},
+										val:        "->",
+										ignoreCase: false,
+										want:       "\"->\"",
+									},
+									&litMatcher{
+										pos:        position{line: 281, col: 55, offset: 6857},
+										val:        "[",
+										ignoreCase: false,
+										want:       "\"[\"",
+									},
+									&litMatcher{
+										pos:        position{line: 281, col: 61, offset: 6863},
+										val:        "]",
+										ignoreCase: false,
+										want:       "\"]\"",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "IfElse",
+			pos:  position{line: 287, col: 1, offset: 6941},
+			expr: &actionExpr{
+				pos: position{line: 287, col: 11, offset: 6951},
+				run: (*parser).callonIfElse1,
+				expr: &seqExpr{
+					pos: position{line: 287, col: 11, offset: 6951},
+					exprs: []any{
+						&labeledExpr{
+							pos:   position{line: 287, col: 11, offset: 6951},
+							label: "lo",
+							expr: &ruleRefExpr{
+								pos:  position{line: 287, col: 14, offset: 6954},
+								name: "Open",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 287, col: 19, offset: 6959},
+							name: "_",
+						},
+						&litMatcher{
+							pos:        position{line: 287, col: 21, offset: 6961},
+							val:        "if",
+							ignoreCase: false,
+							want:       "\"if\"",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 287, col: 26, offset: 6966},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 287, col: 28, offset: 6968},
+							label: "cond",
+							expr: &ruleRefExpr{
+								pos:  position{line: 287, col: 33, offset: 6973},
+								name: "OrCondition",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 287, col: 45, offset: 6985},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 287, col: 47, offset: 6987},
+							label: "ic",
+							expr: &ruleRefExpr{
+								pos:  position{line: 287, col: 50, offset: 6990},
+								name: "Close",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 287, col: 56, offset: 6996},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 287, col: 58, offset: 6998},
+							label: "tr",
+							expr: &ruleRefExpr{
+								pos:  position{line: 287, col: 61, offset: 7001},
+								name: "Seq",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 287, col: 65, offset: 7005},
+							label: "os",
+							expr: &oneOrMoreExpr{
+								pos: position{line: 287, col: 68, offset: 7008},
+								expr: &seqExpr{
+									pos: position{line: 287, col: 69, offset: 7009},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 287, col: 69, offset: 7009},
+											name: "Open",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 287, col: 74, offset: 7014},
+											name: "_",
+										},
+										&litMatcher{
+											pos:        position{line: 287, col: 76, offset: 7016},
+											val:        "else",
+											ignoreCase: false,
+											want:       "\"else\"",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 287, col: 83, offset: 7023},
+											name: "_",
+										},
+										&litMatcher{
+											pos:        position{line: 287, col: 85, offset: 7025},
+											val:        "if",
+											ignoreCase: false,
+											want:       "\"if\"",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 287, col: 90, offset: 7030},
+											name: "_",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 287, col: 92, offset: 7032},
+											name: "OrCondition",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 287, col: 104, offset: 7044},
+											name: "_",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 287, col: 106, offset: 7046},
+											name: "Close",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 287, col: 112, offset: 7052},
+											name: "_",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 287, col: 114, offset: 7054},
+											name: "Seq",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 287, col: 118, offset: 7058},
+											name: "_",
+										},
+									},
+								},
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 287, col: 122, offset: 7062},
+							label: "el",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 287, col: 125, offset: 7065},
+								expr: &seqExpr{
+									pos: position{line: 287, col: 126, offset: 7066},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 287, col: 126, offset: 7066},
+											name: "Open",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 287, col: 131, offset: 7071},
+											name: "_",
+										},
+										&litMatcher{
+											pos:        position{line: 287, col: 133, offset: 7073},
+											val:        "else",
+											ignoreCase: false,
+											want:       "\"else\"",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 287, col: 140, offset: 7080},
+											name: "_",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 287, col: 142, offset: 7082},
+											name: "Close",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 287, col: 149, offset: 7089},
+											name: "_",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 287, col: 151, offset: 7091},
+											name: "Seq",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 287, col: 155, offset: 7095},
+											name: "_",
+										},
+									},
+								},
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 287, col: 160, offset: 7100},
+							label: "eo",
+							expr: &ruleRefExpr{
+								pos:  position{line: 287, col: 163, offset: 7103},
+								name: "Open",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 287, col: 168, offset: 7108},
+							name: "_",
+						},
+						&litMatcher{
+							pos:        position{line: 287, col: 170, offset: 7110},
+							val:        "end",
+							ignoreCase: false,
+							want:       "\"end\"",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 287, col: 176, offset: 7116},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 287, col: 179, offset: 7119},
+							label: "lc",
+							expr: &ruleRefExpr{
+								pos:  position{line: 287, col: 182, offset: 7122},
+								name: "Close",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "If",
+			pos:  position{line: 344, col: 1, offset: 8224},
+			expr: &actionExpr{
+				pos: position{line: 344, col: 7, offset: 8230},
+				run: (*parser).callonIf1,
+				expr: &seqExpr{
+					pos: position{line: 344, col: 7, offset: 8230},
+					exprs: []any{
+						&labeledExpr{
+							pos:   position{line: 344, col: 7, offset: 8230},
+							label: "lo",
+							expr: &ruleRefExpr{
+								pos:  position{line: 344, col: 10, offset: 8233},
+								name: "Open",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 344, col: 15, offset: 8238},
+							name: "_",
+						},
+						&litMatcher{
+							pos:        position{line: 344, col: 17, offset: 8240},
+							val:        "if",
+							ignoreCase: false,
+							want:       "\"if\"",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 344, col: 22, offset: 8245},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 344, col: 24, offset: 8247},
+							label: "cond",
+							expr: &ruleRefExpr{
+								pos:  position{line: 344, col: 29, offset: 8252},
+								name: "OrCondition",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 344, col: 41, offset: 8264},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 344, col: 43, offset: 8266},
+							label: "ic",
+							expr: &ruleRefExpr{
+								pos:  position{line: 344, col: 46, offset: 8269},
+								name: "Close",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 344, col: 52, offset: 8275},
+							label: "tr",
+							expr: &ruleRefExpr{
+								pos:  position{line: 344, col: 55, offset: 8278},
+								name: "Seq",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 344, col: 59, offset: 8282},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 344, col: 61, offset: 8284},
+							label: "f",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 344, col: 63, offset: 8286},
+								expr: &seqExpr{
+									pos: position{line: 344, col: 64, offset: 8287},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 344, col: 64, offset: 8287},
+											name: "Open",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 344, col: 69, offset: 8292},
+											name: "_",
+										},
+										&litMatcher{
+											pos:        position{line: 344, col: 71, offset: 8294},
+											val:        "else",
+											ignoreCase: false,
+											want:       "\"else\"",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 344, col: 78, offset: 8301},
+											name: "_",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 344, col: 80, offset: 8303},
+											name: "Close",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 344, col: 86, offset: 8309},
+											name: "_",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 344, col: 88, offset: 8311},
+											name: "Seq",
+										},
+									},
+								},
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 344, col: 94, offset: 8317},
+							label: "eo",
+							expr: &ruleRefExpr{
+								pos:  position{line: 344, col: 97, offset: 8320},
+								name: "Open",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 344, col: 102, offset: 8325},
+							name: "_",
+						},
+						&litMatcher{
+							pos:        position{line: 344, col: 104, offset: 8327},
+							val:        "end",
+							ignoreCase: false,
+							want:       "\"end\"",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 344, col: 110, offset: 8333},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 344, col: 112, offset: 8335},
+							label: "lc",
+							expr: &ruleRefExpr{
+								pos:  position{line: 344, col: 115, offset: 8338},
+								name: "Close",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "For",
+			pos:  position{line: 379, col: 1, offset: 8934},
+			expr: &actionExpr{
+				pos: position{line: 379, col: 8, offset: 8941},
+				run: (*parser).callonFor1,
+				expr: &seqExpr{
+					pos: position{line: 379, col: 8, offset: 8941},
+					exprs: []any{
+						&labeledExpr{
+							pos:   position{line: 379, col: 8, offset: 8941},
+							label: "lo",
+							expr: &ruleRefExpr{
+								pos:  position{line: 379, col: 11, offset: 8944},
+								name: "Open",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 379, col: 16, offset: 8949},
+							name: "_",
+						},
+						&litMatcher{
+							pos:        position{line: 379, col: 18, offset: 8951},
+							val:        "for",
+							ignoreCase: false,
+							want:       "\"for\"",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 379, col: 24, offset: 8957},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 379, col: 26, offset: 8959},
+							label: "vars",
+							expr: &ruleRefExpr{
+								pos:  position{line: 379, col: 31, offset: 8964},
+								name: "ForVars",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 379, col: 39, offset: 8972},
+							name: "_",
+						},
+						&litMatcher{
+							pos:        position{line: 379, col: 41, offset: 8974},
+							val:        "=",
+							ignoreCase: false,
+							want:       "\"=\"",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 379, col: 45, offset: 8978},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 379, col: 47, offset: 8980},
+							label: "t",
+							expr: &choiceExpr{
+								pos: position{line: 379, col: 50, offset: 8983},
+								alternatives: []any{
+									&litMatcher{
+										pos:        position{line: 379, col: 50, offset: 8983},
+										val:        "range",
+										ignoreCase: false,
+										want:       "\"range\"",
+									},
+									&litMatcher{
+										pos:        position{line: 379, col: 60, offset: 8993},
+										val:        "props",
+										ignoreCase: false,
+										want:       "\"props\"",
+									},
+								},
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 379, col: 69, offset: 9002},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 379, col: 71, offset: 9004},
+							label: "p",
+							expr: &ruleRefExpr{
+								pos:  position{line: 379, col: 73, offset: 9006},
+								name: "Element",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 379, col: 81, offset: 9014},
+							label: "nr",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 379, col: 84, offset: 9017},
+								expr: &seqExpr{
+									pos: position{line: 379, col: 85, offset: 9018},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 379, col: 85, offset: 9018},
+											name: "_",
+										},
+										&litMatcher{
+											pos:        position{line: 379, col: 87, offset: 9020},
+											val:        "..",
+											ignoreCase: false,
+											want:       "\"..\"",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 379, col: 92, offset: 9025},
+											name: "_",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 379, col: 94, offset: 9027},
+											name: "Element",
+										},
+										&zeroOrOneExpr{
+											pos: position{line: 379, col: 102, offset: 9035},
+											expr: &seqExpr{
+												pos: position{line: 379, col: 103, offset: 9036},
+												exprs: []any{
+													&ruleRefExpr{
+														pos:  position{line: 379, col: 103, offset: 9036},
+														name: "_",
+													},
+													&litMatcher{
+														pos:        position{line: 379, col: 105, offset: 9038},
+														val:        "step",
+														ignoreCase: false,
+														want:       "\"step\"",
+													},
+													&ruleRefExpr{
+														pos:  position{line: 379, col: 112, offset: 9045},
+														name: "_",
+													},
+													&ruleRefExpr{
+														pos:  position{line: 379, col: 114, offset: 9047},
+														name: "Element",
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 379, col: 126, offset: 9059},
+							label: "w",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 379, col: 128, offset: 9061},
+								expr: &seqExpr{
+									pos: position{line: 379, col: 129, offset: 9062},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 379, col: 129, offset: 9062},
+											name: "_",
+										},
+										&litMatcher{
+											pos:        position{line: 379, col: 131, offset: 9064},
+											val:        "where",
+											ignoreCase: false,
+											want:       "\"where\"",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 379, col: 139, offset: 9072},
+											name: "_",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 379, col: 141, offset: 9074},
+											name: "OrCondition",
+										},
+									},
+								},
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 379, col: 155, offset: 9088},
+							label: "sb",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 379, col: 158, offset: 9091},
+								expr: &seqExpr{
+									pos: position{line: 379, col: 159, offset: 9092},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 379, col: 159, offset: 9092},
+											name: "_",
+										},
+										&litMatcher{
+											pos:        position{line: 379, col: 161, offset: 9094},
+											val:        "sortby",
+											ignoreCase: false,
+											want:       "\"sortby\"",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 379, col: 170, offset: 9103},
+											name: "_",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 379, col: 172, offset: 9105},
+											name: "Element",
+										},
+										&zeroOrOneExpr{
+											pos: position{line: 379, col: 180, offset: 9113},
+											expr: &seqExpr{
+												pos: position{line: 379, col: 181, offset: 9114},
+												exprs: []any{
+													&ruleRefExpr{
+														pos:  position{line: 379, col: 181, offset: 9114},
+														name: "_",
+													},
+													&litMatcher{
+														pos:        position{line: 379, col: 183, offset: 9116},
+														val:        "desc",
+														ignoreCase: false,
+														want:       "\"desc\"",
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 379, col: 194, offset: 9127},
+							label: "li",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 379, col: 197, offset: 9130},
+								expr: &seqExpr{
+									pos: position{line: 379, col: 198, offset: 9131},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 379, col: 198, offset: 9131},
+											name: "_",
+										},
+										&litMatcher{
+											pos:        position{line: 379, col: 200, offset: 9133},
+											val:        "limit",
+											ignoreCase: false,
+											want:       "\"limit\"",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 379, col: 208, offset: 9141},
+											name: "_",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 379, col: 210, offset: 9143},
+											name: "Element",
+										},
+									},
+								},
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 379, col: 220, offset: 9153},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 379, col: 222, offset: 9155},
+							label: "ic",
+							expr: &ruleRefExpr{
+								pos:  position{line: 379, col: 225, offset: 9158},
+								name: "Close",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 379, col: 231, offset: 9164},
+							label: "l",
+							expr: &ruleRefExpr{
+								pos:  position{line: 379, col: 233, offset: 9166},
+								name: "Seq",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 379, col: 237, offset: 9170},
+							label: "f",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 379, col: 239, offset: 9172},
+								expr: &seqExpr{
+									pos: position{line: 379, col: 240, offset: 9173},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 379, col: 240, offset: 9173},
+											name: "Open",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 379, col: 245, offset: 9178},
+											name: "_",
+										},
+										&litMatcher{
+											pos:        position{line: 379, col: 247, offset: 9180},
+											val:        "else",
+											ignoreCase: false,
+											want:       "\"else\"",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 379, col: 254, offset: 9187},
+											name: "_",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 379, col: 256, offset: 9189},
+											name: "Close",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 379, col: 262, offset: 9195},
+											name: "Seq",
+										},
+									},
+								},
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 379, col: 268, offset: 9201},
+							label: "eo",
+							expr: &ruleRefExpr{
+								pos:  position{line: 379, col: 271, offset: 9204},
+								name: "Open",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 379, col: 276, offset: 9209},
+							name: "_",
+						},
+						&litMatcher{
+							pos:        position{line: 379, col: 278, offset: 9211},
+							val:        "end",
+							ignoreCase: false,
+							want:       "\"end\"",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 379, col: 284, offset: 9217},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 379, col: 286, offset: 9219},
+							label: "lc",
+							expr: &ruleRefExpr{
+								pos:  position{line: 379, col: 289, offset: 9222},
+								name: "Close",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "LoopControl",
+			pos:  position{line: 448, col: 1, offset: 10752},
+			expr: &actionExpr{
+				pos: position{line: 448, col: 16, offset: 10767},
+				run: (*parser).callonLoopControl1,
+				expr: &seqExpr{
+					pos: position{line: 448, col: 16, offset: 10767},
+					exprs: []any{
+						&labeledExpr{
+							pos:   position{line: 448, col: 16, offset: 10767},
+							label: "lo",
+							expr: &ruleRefExpr{
+								pos:  position{line: 448, col: 19, offset: 10770},
+								name: "Open",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 448, col: 24, offset: 10775},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 448, col: 26, offset: 10777},
+							label: "k",
+							expr: &choiceExpr{
+								pos: position{line: 448, col: 29, offset: 10780},
+								alternatives: []any{
+									&litMatcher{
+										pos:        position{line: 448, col: 29, offset: 10780},
+										val:        "break",
+										ignoreCase: false,
+										want:       "\"break\"",
+									},
+									&litMatcher{
+										pos:        position{line: 448, col: 39, offset: 10790},
+										val:        "continue",
+										ignoreCase: false,
+										want:       "\"continue\"",
+									},
+								},
+							},
+						},
+						&notExpr{
+							pos: position{line: 448, col: 51, offset: 10802},
+							expr: &charClassMatcher{
+								pos:        position{line: 448, col: 52, offset: 10803},
+								val:        "[a-zA-Z0-9]",
+								ranges:     []rune{'a', 'z', 'A', 'Z', '0', '9'},
+								ignoreCase: false,
+								inverted:   false,
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 448, col: 64, offset: 10815},
+							label: "w",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 448, col: 66, offset: 10817},
+								expr: &seqExpr{
+									pos: position{line: 448, col: 67, offset: 10818},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 448, col: 67, offset: 10818},
+											name: "_",
+										},
+										&litMatcher{
+											pos:        position{line: 448, col: 69, offset: 10820},
+											val:        "if",
+											ignoreCase: false,
+											want:       "\"if\"",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 448, col: 74, offset: 10825},
+											name: "_",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 448, col: 76, offset: 10827},
+											name: "OrCondition",
+										},
+									},
+								},
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 448, col: 90, offset: 10841},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 448, col: 92, offset: 10843},
+							label: "lc",
+							expr: &ruleRefExpr{
+								pos:  position{line: 448, col: 95, offset: 10846},
+								name: "Close",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Let",
+			pos:  position{line: 459, col: 1, offset: 11109},
+			expr: &actionExpr{
+				pos: position{line: 459, col: 8, offset: 11116},
+				run: (*parser).callonLet1,
+				expr: &seqExpr{
+					pos: position{line: 459, col: 8, offset: 11116},
+					exprs: []any{
+						&labeledExpr{
+							pos:   position{line: 459, col: 8, offset: 11116},
+							label: "lo",
+							expr: &ruleRefExpr{
+								pos:  position{line: 459, col: 11, offset: 11119},
+								name: "Open",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 459, col: 16, offset: 11124},
+							name: "_",
+						},
+						&litMatcher{
+							pos:        position{line: 459, col: 18, offset: 11126},
+							val:        "let",
+							ignoreCase: false,
+							want:       "\"let\"",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 459, col: 24, offset: 11132},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 459, col: 26, offset: 11134},
+							label: "n",
+							expr: &ruleRefExpr{
+								pos:  position{line: 459, col: 28, offset: 11136},
+								name: "VarName",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 459, col: 36, offset: 11144},
+							name: "_",
+						},
+						&litMatcher{
+							pos:        position{line: 459, col: 38, offset: 11146},
+							val:        "=",
+							ignoreCase: false,
+							want:       "\"=\"",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 459, col: 42, offset: 11150},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 459, col: 44, offset: 11152},
+							label: "e",
+							expr: &ruleRefExpr{
+								pos:  position{line: 459, col: 46, offset: 11154},
+								name: "Element",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 459, col: 54, offset: 11162},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 459, col: 56, offset: 11164},
+							label: "lc",
+							expr: &ruleRefExpr{
+								pos:  position{line: 459, col: 59, offset: 11167},
+								name: "Close",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Include",
+			pos:  position{line: 465, col: 1, offset: 11321},
+			expr: &actionExpr{
+				pos: position{line: 465, col: 12, offset: 11332},
+				run: (*parser).callonInclude1,
+				expr: &seqExpr{
+					pos: position{line: 465, col: 12, offset: 11332},
+					exprs: []any{
+						&labeledExpr{
+							pos:   position{line: 465, col: 12, offset: 11332},
+							label: "lo",
+							expr: &ruleRefExpr{
+								pos:  position{line: 465, col: 15, offset: 11335},
+								name: "Open",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 465, col: 20, offset: 11340},
+							name: "_",
+						},
+						&litMatcher{
+							pos:        position{line: 465, col: 22, offset: 11342},
+							val:        "include",
+							ignoreCase: false,
+							want:       "\"include\"",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 465, col: 32, offset: 11352},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 465, col: 34, offset: 11354},
+							label: "p",
+							expr: &ruleRefExpr{
+								pos:  position{line: 465, col: 36, offset: 11356},
+								name: "Quoted",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 465, col: 43, offset: 11363},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 465, col: 45, offset: 11365},
+							label: "lc",
+							expr: &ruleRefExpr{
+								pos:  position{line: 465, col: 48, offset: 11368},
+								name: "Close",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Define",
+			pos:  position{line: 478, col: 1, offset: 11608},
 			expr: &actionExpr{
-				pos: position{line: 133, col: 11, offset: 2380},
-				run: (*parser).callonIfElse1,
+				pos: position{line: 478, col: 11, offset: 11618},
+				run: (*parser).callonDefine1,
 				expr: &seqExpr{
-					pos: position{line: 133, col: 11, offset: 2380},
+					pos: position{line: 478, col: 11, offset: 11618},
 					exprs: []any{
-						&ruleRefExpr{
-							pos:  position{line: 133, col: 11, offset: 2380},
-							name: "S",
+						&labeledExpr{
+							pos:   position{line: 478, col: 11, offset: 11618},
+							label: "lo",
+							expr: &ruleRefExpr{
+								pos:  position{line: 478, col: 14, offset: 11621},
+								name: "Open",
+							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 133, col: 13, offset: 2382},
+							pos:  position{line: 478, col: 19, offset: 11626},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 133, col: 15, offset: 2384},
-							val:        "if",
+							pos:        position{line: 478, col: 21, offset: 11628},
+							val:        "define",
 							ignoreCase: false,
-							want:       "\"if\"",
+							want:       "\"define\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 133, col: 20, offset: 2389},
+							pos:  position{line: 478, col: 30, offset: 11637},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 133, col: 22, offset: 2391},
-							label: "cond",
+							pos:   position{line: 478, col: 32, offset: 11639},
+							label: "n",
 							expr: &ruleRefExpr{
-								pos:  position{line: 133, col: 27, offset: 2396},
-								name: "OrCondition",
+								pos:  position{line: 478, col: 34, offset: 11641},
+								name: "VarName",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 133, col: 39, offset: 2408},
+							pos:  position{line: 478, col: 42, offset: 11649},
 							name: "_",
 						},
+						&litMatcher{
+							pos:        position{line: 478, col: 44, offset: 11651},
+							val:        "(",
+							ignoreCase: false,
+							want:       "\"(\"",
+						},
 						&ruleRefExpr{
-							pos:  position{line: 133, col: 41, offset: 2410},
-							name: "S",
+							pos:  position{line: 478, col: 48, offset: 11655},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 478, col: 50, offset: 11657},
+							label: "ps",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 478, col: 53, offset: 11660},
+								expr: &ruleRefExpr{
+									pos:  position{line: 478, col: 53, offset: 11660},
+									name: "MacroParams",
+								},
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 478, col: 66, offset: 11673},
+							name: "_",
+						},
+						&litMatcher{
+							pos:        position{line: 478, col: 68, offset: 11675},
+							val:        ")",
+							ignoreCase: false,
+							want:       "\")\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 133, col: 43, offset: 2412},
+							pos:  position{line: 478, col: 72, offset: 11679},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 133, col: 45, offset: 2414},
-							label: "tr",
+							pos:   position{line: 478, col: 74, offset: 11681},
+							label: "ic",
+							expr: &ruleRefExpr{
+								pos:  position{line: 478, col: 77, offset: 11684},
+								name: "Close",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 478, col: 83, offset: 11690},
+							label: "b",
 							expr: &ruleRefExpr{
-								pos:  position{line: 133, col: 48, offset: 2417},
+								pos:  position{line: 478, col: 85, offset: 11692},
 								name: "Seq",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 133, col: 52, offset: 2421},
-							label: "os",
-							expr: &oneOrMoreExpr{
-								pos: position{line: 133, col: 55, offset: 2424},
-								expr: &seqExpr{
-									pos: position{line: 133, col: 56, offset: 2425},
-									exprs: []any{
-										&ruleRefExpr{
-											pos:  position{line: 133, col: 56, offset: 2425},
-											name: "S",
-										},
-										&ruleRefExpr{
-											pos:  position{line: 133, col: 58, offset: 2427},
-											name: "_",
-										},
-										&litMatcher{
-											pos:        position{line: 133, col: 60, offset: 2429},
-											val:        "else",
-											ignoreCase: false,
-											want:       "\"else\"",
-										},
-										&ruleRefExpr{
-											pos:  position{line: 133, col: 67, offset: 2436},
-											name: "_",
-										},
-										&litMatcher{
-											pos:        position{line: 133, col: 69, offset: 2438},
-											val:        "if",
-											ignoreCase: false,
-											want:       "\"if\"",
-										},
-										&ruleRefExpr{
-											pos:  position{line: 133, col: 74, offset: 2443},
-											name: "_",
-										},
-										&ruleRefExpr{
-											pos:  position{line: 133, col: 76, offset: 2445},
-											name: "OrCondition",
-										},
-										&ruleRefExpr{
-											pos:  position{line: 133, col: 88, offset: 2457},
-											name: "_",
-										},
-										&ruleRefExpr{
-											pos:  position{line: 133, col: 90, offset: 2459},
-											name: "S",
-										},
-										&ruleRefExpr{
-											pos:  position{line: 133, col: 92, offset: 2461},
-											name: "_",
-										},
-										&ruleRefExpr{
-											pos:  position{line: 133, col: 94, offset: 2463},
-											name: "Seq",
-										},
-										&ruleRefExpr{
-											pos:  position{line: 133, col: 98, offset: 2467},
-											name: "_",
-										},
-									},
-								},
+							pos:   position{line: 478, col: 89, offset: 11696},
+							label: "eo",
+							expr: &ruleRefExpr{
+								pos:  position{line: 478, col: 92, offset: 11699},
+								name: "Open",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 478, col: 97, offset: 11704},
+							name: "_",
+						},
+						&litMatcher{
+							pos:        position{line: 478, col: 99, offset: 11706},
+							val:        "end",
+							ignoreCase: false,
+							want:       "\"end\"",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 478, col: 105, offset: 11712},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 478, col: 107, offset: 11714},
+							label: "lc",
+							expr: &ruleRefExpr{
+								pos:  position{line: 478, col: 110, offset: 11717},
+								name: "Close",
 							},
 						},
+					},
+				},
+			},
+		},
+		{
+			name: "MacroParams",
+			pos:  position{line: 495, col: 1, offset: 12006},
+			expr: &actionExpr{
+				pos: position{line: 495, col: 16, offset: 12021},
+				run: (*parser).callonMacroParams1,
+				expr: &seqExpr{
+					pos: position{line: 495, col: 16, offset: 12021},
+					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 133, col: 102, offset: 2471},
-							label: "el",
-							expr: &zeroOrOneExpr{
-								pos: position{line: 133, col: 105, offset: 2474},
+							pos:   position{line: 495, col: 16, offset: 12021},
+							label: "first",
+							expr: &ruleRefExpr{
+								pos:  position{line: 495, col: 22, offset: 12027},
+								name: "VarName",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 495, col: 30, offset: 12035},
+							label: "rest",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 495, col: 35, offset: 12040},
 								expr: &seqExpr{
-									pos: position{line: 133, col: 106, offset: 2475},
+									pos: position{line: 495, col: 36, offset: 12041},
 									exprs: []any{
 										&ruleRefExpr{
-											pos:  position{line: 133, col: 106, offset: 2475},
-											name: "S",
-										},
-										&ruleRefExpr{
-											pos:  position{line: 133, col: 108, offset: 2477},
+											pos:  position{line: 495, col: 36, offset: 12041},
 											name: "_",
 										},
 										&litMatcher{
-											pos:        position{line: 133, col: 110, offset: 2479},
-											val:        "else",
+											pos:        position{line: 495, col: 38, offset: 12043},
+											val:        ",",
 											ignoreCase: false,
-											want:       "\"else\"",
-										},
-										&ruleRefExpr{
-											pos:  position{line: 133, col: 117, offset: 2486},
-											name: "_",
+											want:       "\",\"",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 133, col: 119, offset: 2488},
-											name: "S",
-										},
-										&ruleRefExpr{
-											pos:  position{line: 133, col: 122, offset: 2491},
+											pos:  position{line: 495, col: 42, offset: 12047},
 											name: "_",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 133, col: 124, offset: 2493},
-											name: "Seq",
-										},
-										&ruleRefExpr{
-											pos:  position{line: 133, col: 128, offset: 2497},
-											name: "_",
+											pos:  position{line: 495, col: 44, offset: 12049},
+											name: "VarName",
 										},
 									},
 								},
 							},
 						},
-						&ruleRefExpr{
-							pos:  position{line: 133, col: 133, offset: 2502},
-							name: "S",
+					},
+				},
+			},
+		},
+		{
+			name: "Extends",
+			pos:  position{line: 507, col: 1, offset: 12277},
+			expr: &actionExpr{
+				pos: position{line: 507, col: 12, offset: 12288},
+				run: (*parser).callonExtends1,
+				expr: &seqExpr{
+					pos: position{line: 507, col: 12, offset: 12288},
+					exprs: []any{
+						&labeledExpr{
+							pos:   position{line: 507, col: 12, offset: 12288},
+							label: "lo",
+							expr: &ruleRefExpr{
+								pos:  position{line: 507, col: 15, offset: 12291},
+								name: "Open",
+							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 133, col: 135, offset: 2504},
+							pos:  position{line: 507, col: 20, offset: 12296},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 133, col: 137, offset: 2506},
-							val:        "end",
+							pos:        position{line: 507, col: 22, offset: 12298},
+							val:        "extends",
 							ignoreCase: false,
-							want:       "\"end\"",
+							want:       "\"extends\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 133, col: 143, offset: 2512},
+							pos:  position{line: 507, col: 32, offset: 12308},
 							name: "_",
 						},
+						&labeledExpr{
+							pos:   position{line: 507, col: 34, offset: 12310},
+							label: "p",
+							expr: &ruleRefExpr{
+								pos:  position{line: 507, col: 36, offset: 12312},
+								name: "Quoted",
+							},
+						},
 						&ruleRefExpr{
-							pos:  position{line: 133, col: 146, offset: 2515},
-							name: "S",
+							pos:  position{line: 507, col: 43, offset: 12319},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 507, col: 45, offset: 12321},
+							label: "lc",
+							expr: &ruleRefExpr{
+								pos:  position{line: 507, col: 48, offset: 12324},
+								name: "Close",
+							},
 						},
 					},
 				},
 			},
 		},
 		{
-			name: "If",
-			pos:  position{line: 179, col: 1, offset: 3464},
+			name: "Block",
+			pos:  position{line: 520, col: 1, offset: 12573},
 			expr: &actionExpr{
-				pos: position{line: 179, col: 7, offset: 3470},
-				run: (*parser).callonIf1,
+				pos: position{line: 520, col: 10, offset: 12582},
+				run: (*parser).callonBlock1,
 				expr: &seqExpr{
-					pos: position{line: 179, col: 7, offset: 3470},
+					pos: position{line: 520, col: 10, offset: 12582},
 					exprs: []any{
-						&ruleRefExpr{
-							pos:  position{line: 179, col: 7, offset: 3470},
-							name: "S",
+						&labeledExpr{
+							pos:   position{line: 520, col: 10, offset: 12582},
+							label: "lo",
+							expr: &ruleRefExpr{
+								pos:  position{line: 520, col: 13, offset: 12585},
+								name: "Open",
+							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 179, col: 9, offset: 3472},
+							pos:  position{line: 520, col: 18, offset: 12590},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 179, col: 11, offset: 3474},
-							val:        "if",
+							pos:        position{line: 520, col: 20, offset: 12592},
+							val:        "block",
 							ignoreCase: false,
-							want:       "\"if\"",
+							want:       "\"block\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 179, col: 16, offset: 3479},
+							pos:  position{line: 520, col: 28, offset: 12600},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 179, col: 18, offset: 3481},
-							label: "cond",
+							pos:   position{line: 520, col: 30, offset: 12602},
+							label: "n",
 							expr: &ruleRefExpr{
-								pos:  position{line: 179, col: 23, offset: 3486},
-								name: "OrCondition",
+								pos:  position{line: 520, col: 32, offset: 12604},
+								name: "VarName",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 179, col: 35, offset: 3498},
+							pos:  position{line: 520, col: 40, offset: 12612},
 							name: "_",
 						},
-						&ruleRefExpr{
-							pos:  position{line: 179, col: 37, offset: 3500},
-							name: "S",
+						&labeledExpr{
+							pos:   position{line: 520, col: 42, offset: 12614},
+							label: "ic",
+							expr: &ruleRefExpr{
+								pos:  position{line: 520, col: 45, offset: 12617},
+								name: "Close",
+							},
 						},
 						&labeledExpr{
-							pos:   position{line: 179, col: 39, offset: 3502},
-							label: "tr",
+							pos:   position{line: 520, col: 51, offset: 12623},
+							label: "b",
 							expr: &ruleRefExpr{
-								pos:  position{line: 179, col: 42, offset: 3505},
+								pos:  position{line: 520, col: 53, offset: 12625},
 								name: "Seq",
 							},
 						},
-						&ruleRefExpr{
-							pos:  position{line: 179, col: 46, offset: 3509},
-							name: "_",
-						},
 						&labeledExpr{
-							pos:   position{line: 179, col: 48, offset: 3511},
-							label: "f",
-							expr: &zeroOrOneExpr{
-								pos: position{line: 179, col: 50, offset: 3513},
-								expr: &seqExpr{
-									pos: position{line: 179, col: 51, offset: 3514},
-									exprs: []any{
-										&ruleRefExpr{
-											pos:  position{line: 179, col: 51, offset: 3514},
-											name: "S",
-										},
-										&ruleRefExpr{
-											pos:  position{line: 179, col: 53, offset: 3516},
-											name: "_",
-										},
-										&litMatcher{
-											pos:        position{line: 179, col: 55, offset: 3518},
-											val:        "else",
-											ignoreCase: false,
-											want:       "\"else\"",
-										},
-										&ruleRefExpr{
-											pos:  position{line: 179, col: 62, offset: 3525},
-											name: "_",
-										},
-										&ruleRefExpr{
-											pos:  position{line: 179, col: 64, offset: 3527},
-											name: "S",
-										},
-										&ruleRefExpr{
-											pos:  position{line: 179, col: 66, offset: 3529},
-											name: "_",
-										},
-										&ruleRefExpr{
-											pos:  position{line: 179, col: 68, offset: 3531},
-											name: "Seq",
-										},
-									},
-								},
+							pos:   position{line: 520, col: 57, offset: 12629},
+							label: "eo",
+							expr: &ruleRefExpr{
+								pos:  position{line: 520, col: 60, offset: 12632},
+								name: "Open",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 179, col: 74, offset: 3537},
-							name: "S",
-						},
-						&ruleRefExpr{
-							pos:  position{line: 179, col: 76, offset: 3539},
+							pos:  position{line: 520, col: 65, offset: 12637},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 179, col: 78, offset: 3541},
+							pos:        position{line: 520, col: 67, offset: 12639},
 							val:        "end",
 							ignoreCase: false,
 							want:       "\"end\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 179, col: 84, offset: 3547},
+							pos:  position{line: 520, col: 73, offset: 12645},
 							name: "_",
 						},
-						&ruleRefExpr{
-							pos:  position{line: 179, col: 86, offset: 3549},
-							name: "S",
+						&labeledExpr{
+							pos:   position{line: 520, col: 75, offset: 12647},
+							label: "lc",
+							expr: &ruleRefExpr{
+								pos:  position{line: 520, col: 78, offset: 12650},
+								name: "Close",
+							},
 						},
 					},
 				},
 			},
 		},
 		{
-			name: "For",
-			pos:  position{line: 193, col: 1, offset: 3871},
+			name: "Quoted",
+			pos:  position{line: 537, col: 1, offset: 12973},
 			expr: &actionExpr{
-				pos: position{line: 193, col: 8, offset: 3878},
-				run: (*parser).callonFor1,
+				pos: position{line: 537, col: 11, offset: 12983},
+				run: (*parser).callonQuoted1,
 				expr: &seqExpr{
-					pos: position{line: 193, col: 8, offset: 3878},
+					pos: position{line: 537, col: 11, offset: 12983},
 					exprs: []any{
-						&ruleRefExpr{
-							pos:  position{line: 193, col: 8, offset: 3878},
-							name: "S",
+						&litMatcher{
+							pos:        position{line: 537, col: 11, offset: 12983},
+							val:        "\"",
+							ignoreCase: false,
+							want:       "\"\\\"\"",
 						},
-						&ruleRefExpr{
-							pos:  position{line: 193, col: 10, offset: 3880},
-							name: "_",
+						&zeroOrMoreExpr{
+							pos: position{line: 537, col: 15, offset: 12987},
+							expr: &charClassMatcher{
+								pos:        position{line: 537, col: 15, offset: 12987},
+								val:        "[^\"]",
+								chars:      []rune{'"'},
+								ignoreCase: false,
+								inverted:   true,
+							},
 						},
 						&litMatcher{
-							pos:        position{line: 193, col: 12, offset: 3882},
-							val:        "for",
+							pos:        position{line: 537, col: 21, offset: 12993},
+							val:        "\"",
 							ignoreCase: false,
-							want:       "\"for\"",
-						},
-						&ruleRefExpr{
-							pos:  position{line: 193, col: 18, offset: 3888},
-							name: "_",
+							want:       "\"\\\"\"",
 						},
+					},
+				},
+			},
+		},
+		{
+			name: "Switch",
+			pos:  position{line: 542, col: 1, offset: 13061},
+			expr: &actionExpr{
+				pos: position{line: 542, col: 11, offset: 13071},
+				run: (*parser).callonSwitch1,
+				expr: &seqExpr{
+					pos: position{line: 542, col: 11, offset: 13071},
+					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 193, col: 20, offset: 3890},
-							label: "vars",
+							pos:   position{line: 542, col: 11, offset: 13071},
+							label: "lo",
 							expr: &ruleRefExpr{
-								pos:  position{line: 193, col: 25, offset: 3895},
-								name: "ForVars",
+								pos:  position{line: 542, col: 14, offset: 13074},
+								name: "Open",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 193, col: 33, offset: 3903},
+							pos:  position{line: 542, col: 19, offset: 13079},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 193, col: 35, offset: 3905},
-							val:        "=",
+							pos:        position{line: 542, col: 21, offset: 13081},
+							val:        "switch",
 							ignoreCase: false,
-							want:       "\"=\"",
+							want:       "\"switch\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 193, col: 39, offset: 3909},
+							pos:  position{line: 542, col: 30, offset: 13090},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 193, col: 41, offset: 3911},
-							label: "t",
-							expr: &choiceExpr{
-								pos: position{line: 193, col: 44, offset: 3914},
-								alternatives: []any{
-									&litMatcher{
-										pos:        position{line: 193, col: 44, offset: 3914},
-										val:        "range",
-										ignoreCase: false,
-										want:       "\"range\"",
+							pos:   position{line: 542, col: 32, offset: 13092},
+							label: "s",
+							expr: &ruleRefExpr{
+								pos:  position{line: 542, col: 34, offset: 13094},
+								name: "Element",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 542, col: 42, offset: 13102},
+							name: "_",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 542, col: 44, offset: 13104},
+							name: "Close",
+						},
+						&zeroOrMoreExpr{
+							pos: position{line: 542, col: 50, offset: 13110},
+							expr: &seqExpr{
+								pos: position{line: 542, col: 51, offset: 13111},
+								exprs: []any{
+									&ruleRefExpr{
+										pos:  position{line: 542, col: 51, offset: 13111},
+										name: "_",
 									},
-									&litMatcher{
-										pos:        position{line: 193, col: 54, offset: 3924},
-										val:        "props",
-										ignoreCase: false,
-										want:       "\"props\"",
+									&ruleRefExpr{
+										pos:  position{line: 542, col: 53, offset: 13113},
+										name: "Comment",
 									},
 								},
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 193, col: 63, offset: 3933},
+							pos:  position{line: 542, col: 63, offset: 13123},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 193, col: 65, offset: 3935},
-							label: "p",
-							expr: &ruleRefExpr{
-								pos:  position{line: 193, col: 67, offset: 3937},
-								name: "Element",
+							pos:   position{line: 542, col: 65, offset: 13125},
+							label: "cs",
+							expr: &oneOrMoreExpr{
+								pos: position{line: 542, col: 68, offset: 13128},
+								expr: &ruleRefExpr{
+									pos:  position{line: 542, col: 68, offset: 13128},
+									name: "Case",
+								},
 							},
 						},
-						&ruleRefExpr{
-							pos:  position{line: 193, col: 75, offset: 3945},
-							name: "_",
-						},
-						&ruleRefExpr{
-							pos:  position{line: 193, col: 77, offset: 3947},
-							name: "S",
+						&labeledExpr{
+							pos:   position{line: 542, col: 74, offset: 13134},
+							label: "d",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 542, col: 76, offset: 13136},
+								expr: &ruleRefExpr{
+									pos:  position{line: 542, col: 76, offset: 13136},
+									name: "Default",
+								},
+							},
 						},
 						&labeledExpr{
-							pos:   position{line: 193, col: 79, offset: 3949},
-							label: "l",
+							pos:   position{line: 542, col: 85, offset: 13145},
+							label: "eo",
 							expr: &ruleRefExpr{
-								pos:  position{line: 193, col: 81, offset: 3951},
-								name: "Seq",
+								pos:  position{line: 542, col: 88, offset: 13148},
+								name: "Open",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 193, col: 85, offset: 3955},
-							name: "S",
-						},
-						&ruleRefExpr{
-							pos:  position{line: 193, col: 87, offset: 3957},
+							pos:  position{line: 542, col: 93, offset: 13153},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 193, col: 89, offset: 3959},
+							pos:        position{line: 542, col: 95, offset: 13155},
 							val:        "end",
 							ignoreCase: false,
 							want:       "\"end\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 193, col: 95, offset: 3965},
+							pos:  position{line: 542, col: 101, offset: 13161},
 							name: "_",
 						},
-						&ruleRefExpr{
-							pos:  position{line: 193, col: 97, offset: 3967},
-							name: "S",
+						&labeledExpr{
+							pos:   position{line: 542, col: 103, offset: 13163},
+							label: "lc",
+							expr: &ruleRefExpr{
+								pos:  position{line: 542, col: 106, offset: 13166},
+								name: "Close",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Case",
+			pos:  position{line: 597, col: 1, offset: 14264},
+			expr: &seqExpr{
+				pos: position{line: 597, col: 9, offset: 14272},
+				exprs: []any{
+					&ruleRefExpr{
+						pos:  position{line: 597, col: 9, offset: 14272},
+						name: "Open",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 597, col: 14, offset: 14277},
+						name: "_",
+					},
+					&litMatcher{
+						pos:        position{line: 597, col: 16, offset: 14279},
+						val:        "case",
+						ignoreCase: false,
+						want:       "\"case\"",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 597, col: 23, offset: 14286},
+						name: "_",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 597, col: 25, offset: 14288},
+						name: "Element",
+					},
+					&zeroOrMoreExpr{
+						pos: position{line: 597, col: 33, offset: 14296},
+						expr: &seqExpr{
+							pos: position{line: 597, col: 34, offset: 14297},
+							exprs: []any{
+								&ruleRefExpr{
+									pos:  position{line: 597, col: 34, offset: 14297},
+									name: "_",
+								},
+								&litMatcher{
+									pos:        position{line: 597, col: 36, offset: 14299},
+									val:        ",",
+									ignoreCase: false,
+									want:       "\",\"",
+								},
+								&ruleRefExpr{
+									pos:  position{line: 597, col: 40, offset: 14303},
+									name: "_",
+								},
+								&ruleRefExpr{
+									pos:  position{line: 597, col: 42, offset: 14305},
+									name: "Element",
+								},
+							},
 						},
 					},
+					&ruleRefExpr{
+						pos:  position{line: 597, col: 52, offset: 14315},
+						name: "_",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 597, col: 54, offset: 14317},
+						name: "Close",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 597, col: 60, offset: 14323},
+						name: "CaseSeq",
+					},
+				},
+			},
+		},
+		{
+			name: "Default",
+			pos:  position{line: 599, col: 1, offset: 14332},
+			expr: &seqExpr{
+				pos: position{line: 599, col: 12, offset: 14343},
+				exprs: []any{
+					&ruleRefExpr{
+						pos:  position{line: 599, col: 12, offset: 14343},
+						name: "Open",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 599, col: 17, offset: 14348},
+						name: "_",
+					},
+					&litMatcher{
+						pos:        position{line: 599, col: 19, offset: 14350},
+						val:        "default",
+						ignoreCase: false,
+						want:       "\"default\"",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 599, col: 29, offset: 14360},
+						name: "_",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 599, col: 31, offset: 14362},
+						name: "Close",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 599, col: 37, offset: 14368},
+						name: "CaseSeq",
+					},
 				},
 			},
 		},
 		{
 			name: "ForVars",
-			pos:  position{line: 204, col: 1, offset: 4274},
+			pos:  position{line: 601, col: 1, offset: 14377},
 			expr: &actionExpr{
-				pos: position{line: 204, col: 12, offset: 4285},
+				pos: position{line: 601, col: 12, offset: 14388},
 				run: (*parser).callonForVars1,
 				expr: &seqExpr{
-					pos: position{line: 204, col: 12, offset: 4285},
+					pos: position{line: 601, col: 12, offset: 14388},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 204, col: 12, offset: 4285},
+							pos:   position{line: 601, col: 12, offset: 14388},
 							label: "v1",
 							expr: &ruleRefExpr{
-								pos:  position{line: 204, col: 15, offset: 4288},
-								name: "VarName",
+								pos:  position{line: 601, col: 15, offset: 14391},
+								name: "LoopVar",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 204, col: 23, offset: 4296},
+							pos:  position{line: 601, col: 23, offset: 14399},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 204, col: 25, offset: 4298},
+							pos:        position{line: 601, col: 25, offset: 14401},
 							val:        ",",
 							ignoreCase: false,
 							want:       "\",\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 204, col: 29, offset: 4302},
+							pos:  position{line: 601, col: 29, offset: 14405},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 204, col: 31, offset: 4304},
+							pos:   position{line: 601, col: 31, offset: 14407},
 							label: "v2",
 							expr: &ruleRefExpr{
-								pos:  position{line: 204, col: 34, offset: 4307},
-								name: "VarName",
+								pos:  position{line: 601, col: 34, offset: 14410},
+								name: "LoopVar",
 							},
 						},
 					},
 				},
 			},
 		},
+		{
+			name: "LoopVar",
+			pos:  position{line: 608, col: 1, offset: 14589},
+			expr: &choiceExpr{
+				pos: position{line: 608, col: 12, offset: 14600},
+				alternatives: []any{
+					&ruleRefExpr{
+						pos:  position{line: 608, col: 12, offset: 14600},
+						name: "VarName",
+					},
+					&actionExpr{
+						pos: position{line: 608, col: 22, offset: 14610},
+						run: (*parser).callonLoopVar3,
+						expr: &litMatcher{
+							pos:        position{line: 608, col: 22, offset: 14610},
+							val:        "_",
+							ignoreCase: false,
+							want:       "\"_\"",
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "VarName",
-			pos:  position{line: 210, col: 1, offset: 4412},
+			pos:  position{line: 612, col: 1, offset: 14636},
 			expr: &actionExpr{
-				pos: position{line: 210, col: 12, offset: 4423},
+				pos: position{line: 612, col: 12, offset: 14647},
 				run: (*parser).callonVarName1,
 				expr: &oneOrMoreExpr{
-					pos: position{line: 210, col: 12, offset: 4423},
+					pos: position{line: 612, col: 12, offset: 14647},
 					expr: &charClassMatcher{
-						pos:        position{line: 210, col: 12, offset: 4423},
+						pos:        position{line: 612, col: 12, offset: 14647},
 						val:        "[a-zA-Z]",
 						ranges:     []rune{'a', 'z', 'A', 'Z'},
 						ignoreCase: false,
@@ -1083,60 +2945,64 @@ var g = &grammar{
 		},
 		{
 			name: "UserFunction",
-			pos:  position{line: 218, col: 1, offset: 4558},
+			pos:  position{line: 620, col: 1, offset: 14774},
 			expr: &actionExpr{
-				pos: position{line: 218, col: 17, offset: 4574},
+				pos: position{line: 620, col: 17, offset: 14790},
 				run: (*parser).callonUserFunction1,
 				expr: &seqExpr{
-					pos: position{line: 218, col: 17, offset: 4574},
+					pos: position{line: 620, col: 17, offset: 14790},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 218, col: 17, offset: 4574},
+							pos:   position{line: 620, col: 17, offset: 14790},
 							label: "n",
 							expr: &ruleRefExpr{
-								pos:  position{line: 218, col: 19, offset: 4576},
+								pos:  position{line: 620, col: 19, offset: 14792},
 								name: "VarName",
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 218, col: 27, offset: 4584},
+							pos:        position{line: 620, col: 27, offset: 14800},
 							val:        "(",
 							ignoreCase: false,
 							want:       "\"(\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 218, col: 31, offset: 4588},
+							pos:  position{line: 620, col: 31, offset: 14804},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 218, col: 33, offset: 4590},
+							pos:   position{line: 620, col: 33, offset: 14806},
 							label: "p",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 218, col: 35, offset: 4592},
+								pos: position{line: 620, col: 35, offset: 14808},
 								expr: &seqExpr{
-									pos: position{line: 218, col: 37, offset: 4594},
+									pos: position{line: 620, col: 37, offset: 14810},
 									exprs: []any{
 										&ruleRefExpr{
-											pos:  position{line: 218, col: 37, offset: 4594},
+											pos:  position{line: 620, col: 37, offset: 14810},
 											name: "Element",
 										},
 										&zeroOrMoreExpr{
-											pos: position{line: 218, col: 46, offset: 4603},
+											pos: position{line: 620, col: 46, offset: 14819},
 											expr: &seqExpr{
-												pos: position{line: 218, col: 47, offset: 4604},
+												pos: position{line: 620, col: 47, offset: 14820},
 												exprs: []any{
+													&ruleRefExpr{
+														pos:  position{line: 620, col: 47, offset: 14820},
+														name: "_",
+													},
 													&litMatcher{
-														pos:        position{line: 218, col: 47, offset: 4604},
+														pos:        position{line: 620, col: 49, offset: 14822},
 														val:        ",",
 														ignoreCase: false,
 														want:       "\",\"",
 													},
 													&ruleRefExpr{
-														pos:  position{line: 218, col: 51, offset: 4608},
+														pos:  position{line: 620, col: 53, offset: 14826},
 														name: "_",
 													},
 													&ruleRefExpr{
-														pos:  position{line: 218, col: 53, offset: 4610},
+														pos:  position{line: 620, col: 55, offset: 14828},
 														name: "Element",
 													},
 												},
@@ -1147,11 +3013,11 @@ var g = &grammar{
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 218, col: 65, offset: 4622},
+							pos:  position{line: 620, col: 67, offset: 14840},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 218, col: 68, offset: 4625},
+							pos:        position{line: 620, col: 70, offset: 14843},
 							val:        ")",
 							ignoreCase: false,
 							want:       "\")\"",
@@ -1160,47 +3026,72 @@ var g = &grammar{
 				},
 			},
 		},
+		{
+			name: "PipeStage",
+			pos:  position{line: 648, col: 1, offset: 15533},
+			expr: &actionExpr{
+				pos: position{line: 648, col: 14, offset: 15546},
+				run: (*parser).callonPipeStage1,
+				expr: &labeledExpr{
+					pos:   position{line: 648, col: 14, offset: 15546},
+					label: "f",
+					expr: &choiceExpr{
+						pos: position{line: 648, col: 17, offset: 15549},
+						alternatives: []any{
+							&ruleRefExpr{
+								pos:  position{line: 648, col: 17, offset: 15549},
+								name: "UserFunction",
+							},
+							&ruleRefExpr{
+								pos:  position{line: 648, col: 32, offset: 15564},
+								name: "VarName",
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "OrCondition",
-			pos:  position{line: 239, col: 1, offset: 5052},
+			pos:  position{line: 656, col: 1, offset: 15699},
 			expr: &actionExpr{
-				pos: position{line: 239, col: 16, offset: 5067},
+				pos: position{line: 656, col: 16, offset: 15714},
 				run: (*parser).callonOrCondition1,
 				expr: &seqExpr{
-					pos: position{line: 239, col: 16, offset: 5067},
+					pos: position{line: 656, col: 16, offset: 15714},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 239, col: 16, offset: 5067},
+							pos:   position{line: 656, col: 16, offset: 15714},
 							label: "base",
 							expr: &ruleRefExpr{
-								pos:  position{line: 239, col: 21, offset: 5072},
+								pos:  position{line: 656, col: 21, offset: 15719},
 								name: "AndCondition",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 239, col: 34, offset: 5085},
+							pos:   position{line: 656, col: 34, offset: 15732},
 							label: "os",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 239, col: 37, offset: 5088},
+								pos: position{line: 656, col: 37, offset: 15735},
 								expr: &seqExpr{
-									pos: position{line: 239, col: 39, offset: 5090},
+									pos: position{line: 656, col: 39, offset: 15737},
 									exprs: []any{
 										&ruleRefExpr{
-											pos:  position{line: 239, col: 39, offset: 5090},
+											pos:  position{line: 656, col: 39, offset: 15737},
 											name: "_",
 										},
 										&litMatcher{
-											pos:        position{line: 239, col: 41, offset: 5092},
+											pos:        position{line: 656, col: 41, offset: 15739},
 											val:        "or",
 											ignoreCase: false,
 											want:       "\"or\"",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 239, col: 47, offset: 5098},
+											pos:  position{line: 656, col: 47, offset: 15745},
 											name: "_",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 239, col: 49, offset: 5100},
+											pos:  position{line: 656, col: 49, offset: 15747},
 											name: "AndCondition",
 										},
 									},
@@ -1213,45 +3104,45 @@ var g = &grammar{
 		},
 		{
 			name: "AndCondition",
-			pos:  position{line: 263, col: 1, offset: 5495},
+			pos:  position{line: 680, col: 1, offset: 16118},
 			expr: &actionExpr{
-				pos: position{line: 263, col: 17, offset: 5511},
+				pos: position{line: 680, col: 17, offset: 16134},
 				run: (*parser).callonAndCondition1,
 				expr: &seqExpr{
-					pos: position{line: 263, col: 17, offset: 5511},
+					pos: position{line: 680, col: 17, offset: 16134},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 263, col: 17, offset: 5511},
+							pos:   position{line: 680, col: 17, offset: 16134},
 							label: "base",
 							expr: &ruleRefExpr{
-								pos:  position{line: 263, col: 22, offset: 5516},
+								pos:  position{line: 680, col: 22, offset: 16139},
 								name: "Condition",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 263, col: 32, offset: 5526},
+							pos:   position{line: 680, col: 32, offset: 16149},
 							label: "os",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 263, col: 35, offset: 5529},
+								pos: position{line: 680, col: 35, offset: 16152},
 								expr: &seqExpr{
-									pos: position{line: 263, col: 37, offset: 5531},
+									pos: position{line: 680, col: 37, offset: 16154},
 									exprs: []any{
 										&ruleRefExpr{
-											pos:  position{line: 263, col: 37, offset: 5531},
+											pos:  position{line: 680, col: 37, offset: 16154},
 											name: "_",
 										},
 										&litMatcher{
-											pos:        position{line: 263, col: 39, offset: 5533},
+											pos:        position{line: 680, col: 39, offset: 16156},
 											val:        "and",
 											ignoreCase: false,
 											want:       "\"and\"",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 263, col: 45, offset: 5539},
+											pos:  position{line: 680, col: 45, offset: 16162},
 											name: "_",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 263, col: 48, offset: 5542},
+											pos:  position{line: 680, col: 48, offset: 16165},
 											name: "Condition",
 										},
 									},
@@ -1264,42 +3155,42 @@ var g = &grammar{
 		},
 		{
 			name: "Condition",
-			pos:  position{line: 285, col: 1, offset: 5931},
+			pos:  position{line: 702, col: 1, offset: 16532},
 			expr: &actionExpr{
-				pos: position{line: 285, col: 14, offset: 5944},
+				pos: position{line: 702, col: 14, offset: 16545},
 				run: (*parser).callonCondition1,
 				expr: &labeledExpr{
-					pos:   position{line: 285, col: 14, offset: 5944},
+					pos:   position{line: 702, col: 14, offset: 16545},
 					label: "e",
 					expr: &choiceExpr{
-						pos: position{line: 285, col: 18, offset: 5948},
+						pos: position{line: 702, col: 18, offset: 16549},
 						alternatives: []any{
 							&ruleRefExpr{
-								pos:  position{line: 285, col: 18, offset: 5948},
-								name: "OfType",
+								pos:  position{line: 702, col: 18, offset: 16549},
+								name: "Exists",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 285, col: 27, offset: 5957},
-								name: "Exists",
+								pos:  position{line: 702, col: 27, offset: 16558},
+								name: "ElementCondition",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 285, col: 36, offset: 5966},
-								name: "FromElements",
+								pos:  position{line: 702, col: 46, offset: 16577},
+								name: "NegatedElement",
 							},
 							&seqExpr{
-								pos: position{line: 285, col: 51, offset: 5981},
+								pos: position{line: 702, col: 63, offset: 16594},
 								exprs: []any{
 									&zeroOrOneExpr{
-										pos: position{line: 285, col: 51, offset: 5981},
+										pos: position{line: 702, col: 63, offset: 16594},
 										expr: &litMatcher{
-											pos:        position{line: 285, col: 52, offset: 5982},
+											pos:        position{line: 702, col: 64, offset: 16595},
 											val:        "!",
 											ignoreCase: false,
 											want:       "\"!\"",
 										},
 									},
 									&ruleRefExpr{
-										pos:  position{line: 285, col: 58, offset: 5988},
+										pos:  position{line: 702, col: 70, offset: 16601},
 										name: "GroupedCondition",
 									},
 								},
@@ -1310,41 +3201,101 @@ var g = &grammar{
 			},
 		},
 		{
-			name: "OfType",
-			pos:  position{line: 304, col: 1, offset: 6255},
+			name: "ElementCondition",
+			pos:  position{line: 723, col: 1, offset: 17038},
 			expr: &actionExpr{
-				pos: position{line: 304, col: 12, offset: 6266},
-				run: (*parser).callonOfType1,
+				pos: position{line: 723, col: 21, offset: 17058},
+				run: (*parser).callonElementCondition1,
 				expr: &seqExpr{
-					pos: position{line: 304, col: 12, offset: 6266},
+					pos: position{line: 723, col: 21, offset: 17058},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 304, col: 12, offset: 6266},
+							pos:   position{line: 723, col: 21, offset: 17058},
 							label: "el",
 							expr: &ruleRefExpr{
-								pos:  position{line: 304, col: 15, offset: 6269},
-								name: "Element",
+								pos:  position{line: 723, col: 24, offset: 17061},
+								name: "Coalesce",
 							},
 						},
-						&ruleRefExpr{
-							pos:  position{line: 304, col: 23, offset: 6277},
-							name: "_",
+						&labeledExpr{
+							pos:   position{line: 723, col: 33, offset: 17070},
+							label: "t",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 723, col: 35, offset: 17072},
+								expr: &choiceExpr{
+									pos: position{line: 723, col: 36, offset: 17073},
+									alternatives: []any{
+										&seqExpr{
+											pos: position{line: 723, col: 36, offset: 17073},
+											exprs: []any{
+												&ruleRefExpr{
+													pos:  position{line: 723, col: 36, offset: 17073},
+													name: "_",
+												},
+												&ruleRefExpr{
+													pos:  position{line: 723, col: 38, offset: 17075},
+													name: "OfType",
+												},
+											},
+										},
+										&seqExpr{
+											pos: position{line: 723, col: 47, offset: 17084},
+											exprs: []any{
+												&ruleRefExpr{
+													pos:  position{line: 723, col: 47, offset: 17084},
+													name: "_",
+												},
+												&ruleRefExpr{
+													pos:  position{line: 723, col: 49, offset: 17086},
+													name: "Matches",
+												},
+											},
+										},
+										&seqExpr{
+											pos: position{line: 723, col: 59, offset: 17096},
+											exprs: []any{
+												&ruleRefExpr{
+													pos:  position{line: 723, col: 59, offset: 17096},
+													name: "_",
+												},
+												&ruleRefExpr{
+													pos:  position{line: 723, col: 61, offset: 17098},
+													name: "Comparison",
+												},
+											},
+										},
+									},
+								},
+							},
 						},
+					},
+				},
+			},
+		},
+		{
+			name: "OfType",
+			pos:  position{line: 747, col: 1, offset: 17593},
+			expr: &actionExpr{
+				pos: position{line: 747, col: 11, offset: 17603},
+				run: (*parser).callonOfType1,
+				expr: &seqExpr{
+					pos: position{line: 747, col: 11, offset: 17603},
+					exprs: []any{
 						&litMatcher{
-							pos:        position{line: 304, col: 25, offset: 6279},
+							pos:        position{line: 747, col: 11, offset: 17603},
 							val:        "isa",
 							ignoreCase: false,
 							want:       "\"isa\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 304, col: 31, offset: 6285},
+							pos:  position{line: 747, col: 17, offset: 17609},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 304, col: 33, offset: 6287},
+							pos:   position{line: 747, col: 19, offset: 17611},
 							label: "e",
 							expr: &ruleRefExpr{
-								pos:  position{line: 304, col: 35, offset: 6289},
+								pos:  position{line: 747, col: 21, offset: 17613},
 								name: "TypeExpression",
 							},
 						},
@@ -1354,68 +3305,181 @@ var g = &grammar{
 		},
 		{
 			name: "TypeExpression",
-			pos:  position{line: 311, col: 1, offset: 6462},
+			pos:  position{line: 752, col: 1, offset: 17719},
 			expr: &choiceExpr{
-				pos: position{line: 311, col: 19, offset: 6480},
+				pos: position{line: 752, col: 19, offset: 17737},
 				alternatives: []any{
 					&litMatcher{
-						pos:        position{line: 311, col: 19, offset: 6480},
+						pos:        position{line: 752, col: 19, offset: 17737},
 						val:        "array",
 						ignoreCase: false,
 						want:       "\"array\"",
 					},
 					&litMatcher{
-						pos:        position{line: 311, col: 29, offset: 6490},
+						pos:        position{line: 752, col: 29, offset: 17747},
 						val:        "object",
 						ignoreCase: false,
 						want:       "\"object\"",
 					},
 					&litMatcher{
-						pos:        position{line: 311, col: 40, offset: 6501},
+						pos:        position{line: 752, col: 40, offset: 17758},
 						val:        "number",
 						ignoreCase: false,
 						want:       "\"number\"",
 					},
 					&litMatcher{
-						pos:        position{line: 311, col: 51, offset: 6512},
+						pos:        position{line: 752, col: 51, offset: 17769},
 						val:        "string",
 						ignoreCase: false,
 						want:       "\"string\"",
 					},
 					&litMatcher{
-						pos:        position{line: 311, col: 62, offset: 6523},
+						pos:        position{line: 752, col: 62, offset: 17780},
 						val:        "bool",
 						ignoreCase: false,
 						want:       "\"bool\"",
 					},
+					&litMatcher{
+						pos:        position{line: 752, col: 71, offset: 17789},
+						val:        "date",
+						ignoreCase: false,
+						want:       "\"date\"",
+					},
+					&litMatcher{
+						pos:        position{line: 752, col: 80, offset: 17798},
+						val:        "null",
+						ignoreCase: false,
+						want:       "\"null\"",
+					},
+				},
+			},
+		},
+		{
+			name: "Matches",
+			pos:  position{line: 755, col: 1, offset: 17905},
+			expr: &actionExpr{
+				pos: position{line: 755, col: 12, offset: 17916},
+				run: (*parser).callonMatches1,
+				expr: &seqExpr{
+					pos: position{line: 755, col: 12, offset: 17916},
+					exprs: []any{
+						&litMatcher{
+							pos:        position{line: 755, col: 12, offset: 17916},
+							val:        "matches",
+							ignoreCase: false,
+							want:       "\"matches\"",
+						},
+						&notExpr{
+							pos: position{line: 755, col: 22, offset: 17926},
+							expr: &charClassMatcher{
+								pos:        position{line: 755, col: 23, offset: 17927},
+								val:        "[a-zA-Z0-9]",
+								ranges:     []rune{'a', 'z', 'A', 'Z', '0', '9'},
+								ignoreCase: false,
+								inverted:   false,
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 755, col: 35, offset: 17939},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 755, col: 37, offset: 17941},
+							label: "r",
+							expr: &ruleRefExpr{
+								pos:  position{line: 755, col: 39, offset: 17943},
+								name: "Quoted",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Comparison",
+			pos:  position{line: 762, col: 1, offset: 18132},
+			expr: &actionExpr{
+				pos: position{line: 762, col: 15, offset: 18146},
+				run: (*parser).callonComparison1,
+				expr: &seqExpr{
+					pos: position{line: 762, col: 15, offset: 18146},
+					exprs: []any{
+						&labeledExpr{
+							pos:   position{line: 762, col: 15, offset: 18146},
+							label: "o",
+							expr: &ruleRefExpr{
+								pos:  position{line: 762, col: 17, offset: 18148},
+								name: "Operator",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 762, col: 26, offset: 18157},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 762, col: 28, offset: 18159},
+							label: "r",
+							expr: &ruleRefExpr{
+								pos:  position{line: 762, col: 30, offset: 18161},
+								name: "Coalesce",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "NegatedElement",
+			pos:  position{line: 767, col: 1, offset: 18345},
+			expr: &actionExpr{
+				pos: position{line: 767, col: 19, offset: 18363},
+				run: (*parser).callonNegatedElement1,
+				expr: &seqExpr{
+					pos: position{line: 767, col: 19, offset: 18363},
+					exprs: []any{
+						&litMatcher{
+							pos:        position{line: 767, col: 19, offset: 18363},
+							val:        "!",
+							ignoreCase: false,
+							want:       "\"!\"",
+						},
+						&labeledExpr{
+							pos:   position{line: 767, col: 23, offset: 18367},
+							label: "e",
+							expr: &ruleRefExpr{
+								pos:  position{line: 767, col: 25, offset: 18369},
+								name: "Coalesce",
+							},
+						},
+					},
 				},
 			},
 		},
 		{
 			name: "Exists",
-			pos:  position{line: 313, col: 1, offset: 6534},
+			pos:  position{line: 771, col: 1, offset: 18462},
 			expr: &actionExpr{
-				pos: position{line: 313, col: 11, offset: 6544},
+				pos: position{line: 771, col: 11, offset: 18472},
 				run: (*parser).callonExists1,
 				expr: &seqExpr{
-					pos: position{line: 313, col: 11, offset: 6544},
+					pos: position{line: 771, col: 11, offset: 18472},
 					exprs: []any{
 						&litMatcher{
-							pos:        position{line: 313, col: 11, offset: 6544},
+							pos:        position{line: 771, col: 11, offset: 18472},
 							val:        "exists",
 							ignoreCase: false,
 							want:       "\"exists\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 313, col: 20, offset: 6553},
+							pos:  position{line: 771, col: 20, offset: 18481},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 313, col: 22, offset: 6555},
+							pos:   position{line: 771, col: 22, offset: 18483},
 							label: "p",
 							expr: &ruleRefExpr{
-								pos:  position{line: 313, col: 24, offset: 6557},
-								name: "Element",
+								pos:  position{line: 771, col: 24, offset: 18485},
+								name: "Coalesce",
 							},
 						},
 					},
@@ -1424,97 +3488,130 @@ var g = &grammar{
 		},
 		{
 			name: "GroupedCondition",
-			pos:  position{line: 319, col: 1, offset: 6651},
+			pos:  position{line: 777, col: 1, offset: 18574},
 			expr: &actionExpr{
-				pos: position{line: 319, col: 21, offset: 6671},
+				pos: position{line: 777, col: 21, offset: 18594},
 				run: (*parser).callonGroupedCondition1,
 				expr: &seqExpr{
-					pos: position{line: 319, col: 21, offset: 6671},
+					pos: position{line: 777, col: 21, offset: 18594},
 					exprs: []any{
 						&litMatcher{
-							pos:        position{line: 319, col: 21, offset: 6671},
+							pos:        position{line: 777, col: 21, offset: 18594},
 							val:        "(",
 							ignoreCase: false,
 							want:       "\"(\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 319, col: 25, offset: 6675},
+							pos:  position{line: 777, col: 25, offset: 18598},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 777, col: 27, offset: 18600},
+							label: "cond",
+							expr: &ruleRefExpr{
+								pos:  position{line: 777, col: 32, offset: 18605},
+								name: "OrCondition",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 777, col: 44, offset: 18617},
 							name: "_",
 						},
-						&labeledExpr{
-							pos:   position{line: 319, col: 27, offset: 6677},
-							label: "cond",
-							expr: &ruleRefExpr{
-								pos:  position{line: 319, col: 32, offset: 6682},
-								name: "OrCondition",
-							},
+						&litMatcher{
+							pos:        position{line: 777, col: 46, offset: 18619},
+							val:        ")",
+							ignoreCase: false,
+							want:       "\")\"",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Operator",
+			pos:  position{line: 781, col: 1, offset: 18647},
+			expr: &actionExpr{
+				pos: position{line: 781, col: 13, offset: 18659},
+				run: (*parser).callonOperator1,
+				expr: &choiceExpr{
+					pos: position{line: 781, col: 14, offset: 18660},
+					alternatives: []any{
+						&litMatcher{
+							pos:        position{line: 781, col: 14, offset: 18660},
+							val:        "=",
+							ignoreCase: false,
+							want:       "\"=\"",
+						},
+						&litMatcher{
+							pos:        position{line: 781, col: 20, offset: 18666},
+							val:        "<=",
+							ignoreCase: false,
+							want:       "\"<=\"",
+						},
+						&litMatcher{
+							pos:        position{line: 781, col: 27, offset: 18673},
+							val:        ">=",
+							ignoreCase: false,
+							want:       "\">=\"",
+						},
+						&litMatcher{
+							pos:        position{line: 781, col: 34, offset: 18680},
+							val:        "<",
+							ignoreCase: false,
+							want:       "\"<\"",
 						},
-						&ruleRefExpr{
-							pos:  position{line: 319, col: 44, offset: 6694},
-							name: "_",
+						&litMatcher{
+							pos:        position{line: 781, col: 40, offset: 18686},
+							val:        ">",
+							ignoreCase: false,
+							want:       "\">\"",
 						},
 						&litMatcher{
-							pos:        position{line: 319, col: 46, offset: 6696},
-							val:        ")",
+							pos:        position{line: 781, col: 46, offset: 18692},
+							val:        "!=",
 							ignoreCase: false,
-							want:       "\")\"",
+							want:       "\"!=\"",
 						},
-					},
-				},
-			},
-		},
-		{
-			name: "FromElements",
-			pos:  position{line: 323, col: 1, offset: 6728},
-			expr: &actionExpr{
-				pos: position{line: 323, col: 17, offset: 6744},
-				run: (*parser).callonFromElements1,
-				expr: &labeledExpr{
-					pos:   position{line: 323, col: 17, offset: 6744},
-					label: "e",
-					expr: &choiceExpr{
-						pos: position{line: 323, col: 20, offset: 6747},
-						alternatives: []any{
-							&seqExpr{
-								pos: position{line: 323, col: 20, offset: 6747},
-								exprs: []any{
-									&ruleRefExpr{
-										pos:  position{line: 323, col: 20, offset: 6747},
-										name: "Element",
-									},
-									&ruleRefExpr{
-										pos:  position{line: 323, col: 28, offset: 6755},
-										name: "_",
-									},
-									&ruleRefExpr{
-										pos:  position{line: 323, col: 30, offset: 6757},
-										name: "Operator",
-									},
-									&ruleRefExpr{
-										pos:  position{line: 323, col: 39, offset: 6766},
-										name: "_",
-									},
-									&ruleRefExpr{
-										pos:  position{line: 323, col: 41, offset: 6768},
-										name: "Element",
-									},
-								},
-							},
-							&seqExpr{
-								pos: position{line: 323, col: 51, offset: 6778},
-								exprs: []any{
-									&zeroOrOneExpr{
-										pos: position{line: 323, col: 52, offset: 6779},
-										expr: &litMatcher{
-											pos:        position{line: 323, col: 52, offset: 6779},
-											val:        "!",
+						&seqExpr{
+							pos: position{line: 781, col: 53, offset: 18699},
+							exprs: []any{
+								&choiceExpr{
+									pos: position{line: 781, col: 54, offset: 18700},
+									alternatives: []any{
+										&litMatcher{
+											pos:        position{line: 781, col: 54, offset: 18700},
+											val:        "in",
 											ignoreCase: false,
-											want:       "\"!\"",
+											want:       "\"in\"",
+										},
+										&litMatcher{
+											pos:        position{line: 781, col: 61, offset: 18707},
+											val:        "contains",
+											ignoreCase: false,
+											want:       "\"contains\"",
+										},
+										&litMatcher{
+											pos:        position{line: 781, col: 74, offset: 18720},
+											val:        "startswith",
+											ignoreCase: false,
+											want:       "\"startswith\"",
+										},
+										&litMatcher{
+											pos:        position{line: 781, col: 89, offset: 18735},
+											val:        "endswith",
+											ignoreCase: false,
+											want:       "\"endswith\"",
 										},
 									},
-									&ruleRefExpr{
-										pos:  position{line: 323, col: 58, offset: 6785},
-										name: "Element",
+								},
+								&notExpr{
+									pos: position{line: 781, col: 101, offset: 18747},
+									expr: &charClassMatcher{
+										pos:        position{line: 781, col: 102, offset: 18748},
+										val:        "[a-zA-Z0-9]",
+										ranges:     []rune{'a', 'z', 'A', 'Z', '0', '9'},
+										ignoreCase: false,
+										inverted:   false,
 									},
 								},
 							},
@@ -1523,113 +3620,79 @@ var g = &grammar{
 				},
 			},
 		},
-		{
-			name: "Operator",
-			pos:  position{line: 357, col: 1, offset: 7509},
-			expr: &choiceExpr{
-				pos: position{line: 357, col: 13, offset: 7521},
-				alternatives: []any{
-					&litMatcher{
-						pos:        position{line: 357, col: 13, offset: 7521},
-						val:        "=",
-						ignoreCase: false,
-						want:       "\"=\"",
-					},
-					&litMatcher{
-						pos:        position{line: 357, col: 19, offset: 7527},
-						val:        "<=",
-						ignoreCase: false,
-						want:       "\"<=\"",
-					},
-					&litMatcher{
-						pos:        position{line: 357, col: 26, offset: 7534},
-						val:        ">=",
-						ignoreCase: false,
-						want:       "\">=\"",
-					},
-					&litMatcher{
-						pos:        position{line: 357, col: 33, offset: 7541},
-						val:        "<",
-						ignoreCase: false,
-						want:       "\"<\"",
-					},
-					&litMatcher{
-						pos:        position{line: 357, col: 39, offset: 7547},
-						val:        ">",
-						ignoreCase: false,
-						want:       "\">\"",
-					},
-					&litMatcher{
-						pos:        position{line: 357, col: 45, offset: 7553},
-						val:        "!=",
-						ignoreCase: false,
-						want:       "\"!=\"",
-					},
-				},
-			},
-		},
 		{
 			name: "Text",
-			pos:  position{line: 359, col: 1, offset: 7561},
+			pos:  position{line: 786, col: 1, offset: 18870},
 			expr: &actionExpr{
-				pos: position{line: 359, col: 9, offset: 7569},
+				pos: position{line: 786, col: 9, offset: 18878},
 				run: (*parser).callonText1,
 				expr: &oneOrMoreExpr{
-					pos: position{line: 359, col: 9, offset: 7569},
-					expr: &charClassMatcher{
-						pos:        position{line: 359, col: 9, offset: 7569},
-						val:        "[^$]",
-						chars:      []rune{'$'},
-						ignoreCase: false,
-						inverted:   true,
+					pos: position{line: 786, col: 9, offset: 18878},
+					expr: &choiceExpr{
+						pos: position{line: 786, col: 10, offset: 18879},
+						alternatives: []any{
+							&charClassMatcher{
+								pos:        position{line: 786, col: 10, offset: 18879},
+								val:        "[^$]",
+								chars:      []rune{'$'},
+								ignoreCase: false,
+								inverted:   true,
+							},
+							&litMatcher{
+								pos:        position{line: 786, col: 17, offset: 18886},
+								val:        "$$",
+								ignoreCase: false,
+								want:       "\"$$\"",
+							},
+						},
 					},
 				},
 			},
 		},
 		{
 			name: "Special",
-			pos:  position{line: 364, col: 1, offset: 7629},
+			pos:  position{line: 791, col: 1, offset: 18973},
 			expr: &choiceExpr{
-				pos: position{line: 364, col: 12, offset: 7640},
+				pos: position{line: 791, col: 12, offset: 18984},
 				alternatives: []any{
 					&litMatcher{
-						pos:        position{line: 364, col: 12, offset: 7640},
+						pos:        position{line: 791, col: 12, offset: 18984},
 						val:        "for",
 						ignoreCase: false,
 						want:       "\"for\"",
 					},
 					&litMatcher{
-						pos:        position{line: 364, col: 20, offset: 7648},
+						pos:        position{line: 791, col: 20, offset: 18992},
 						val:        "if",
 						ignoreCase: false,
 						want:       "\"if\"",
 					},
 					&litMatcher{
-						pos:        position{line: 364, col: 27, offset: 7655},
+						pos:        position{line: 791, col: 27, offset: 18999},
 						val:        "range",
 						ignoreCase: false,
 						want:       "\"range\"",
 					},
 					&litMatcher{
-						pos:        position{line: 364, col: 37, offset: 7665},
+						pos:        position{line: 791, col: 37, offset: 19009},
 						val:        "props",
 						ignoreCase: false,
 						want:       "\"props\"",
 					},
 					&litMatcher{
-						pos:        position{line: 364, col: 47, offset: 7675},
+						pos:        position{line: 791, col: 47, offset: 19019},
 						val:        "exists",
 						ignoreCase: false,
 						want:       "\"exists\"",
 					},
 					&litMatcher{
-						pos:        position{line: 364, col: 58, offset: 7686},
+						pos:        position{line: 791, col: 58, offset: 19030},
 						val:        "end",
 						ignoreCase: false,
 						want:       "\"end\"",
 					},
 					&litMatcher{
-						pos:        position{line: 364, col: 66, offset: 7694},
+						pos:        position{line: 791, col: 66, offset: 19038},
 						val:        "else",
 						ignoreCase: false,
 						want:       "\"else\"",
@@ -1639,22 +3702,98 @@ var g = &grammar{
 		},
 		{
 			name: "S",
-			pos:  position{line: 366, col: 1, offset: 7705},
+			pos:  position{line: 793, col: 1, offset: 19047},
 			expr: &litMatcher{
-				pos:        position{line: 366, col: 6, offset: 7710},
+				pos:        position{line: 793, col: 6, offset: 19052},
 				val:        "$",
 				ignoreCase: false,
 				want:       "\"$\"",
 			},
 		},
+		{
+			name: "Open",
+			pos:  position{line: 797, col: 1, offset: 19238},
+			expr: &actionExpr{
+				pos: position{line: 797, col: 9, offset: 19246},
+				run: (*parser).callonOpen1,
+				expr: &seqExpr{
+					pos: position{line: 797, col: 9, offset: 19246},
+					exprs: []any{
+						&ruleRefExpr{
+							pos:  position{line: 797, col: 9, offset: 19246},
+							name: "S",
+						},
+						&labeledExpr{
+							pos:   position{line: 797, col: 11, offset: 19248},
+							label: "t",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 797, col: 13, offset: 19250},
+								expr: &seqExpr{
+									pos: position{line: 797, col: 14, offset: 19251},
+									exprs: []any{
+										&litMatcher{
+											pos:        position{line: 797, col: 14, offset: 19251},
+											val:        "-",
+											ignoreCase: false,
+											want:       "\"-\"",
+										},
+										&notExpr{
+											pos: position{line: 797, col: 18, offset: 19255},
+											expr: &charClassMatcher{
+												pos:        position{line: 797, col: 19, offset: 19256},
+												val:        "[0-9a-zA-Z(]",
+												chars:      []rune{'('},
+												ranges:     []rune{'0', '9', 'a', 'z', 'A', 'Z'},
+												ignoreCase: false,
+												inverted:   false,
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Close",
+			pos:  position{line: 802, col: 1, offset: 19399},
+			expr: &actionExpr{
+				pos: position{line: 802, col: 10, offset: 19408},
+				run: (*parser).callonClose1,
+				expr: &seqExpr{
+					pos: position{line: 802, col: 10, offset: 19408},
+					exprs: []any{
+						&labeledExpr{
+							pos:   position{line: 802, col: 10, offset: 19408},
+							label: "t",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 802, col: 12, offset: 19410},
+								expr: &litMatcher{
+									pos:        position{line: 802, col: 12, offset: 19410},
+									val:        "-",
+									ignoreCase: false,
+									want:       "\"-\"",
+								},
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 802, col: 17, offset: 19415},
+							name: "S",
+						},
+					},
+				},
+			},
+		},
 		{
 			name:        "_",
 			displayName: "\"whitespace\"",
-			pos:         position{line: 368, col: 1, offset: 7717},
+			pos:         position{line: 806, col: 1, offset: 19444},
 			expr: &zeroOrMoreExpr{
-				pos: position{line: 368, col: 19, offset: 7735},
+				pos: position{line: 806, col: 19, offset: 19462},
 				expr: &charClassMatcher{
-					pos:        position{line: 368, col: 19, offset: 7735},
+					pos:        position{line: 806, col: 19, offset: 19462},
 					val:        "[ \\t\\r\\n]",
 					chars:      []rune{' ', '\t', '\r', '\n'},
 					ignoreCase: false,
@@ -1664,36 +3803,36 @@ var g = &grammar{
 		},
 		{
 			name: "EOF",
-			pos:  position{line: 370, col: 1, offset: 7749},
+			pos:  position{line: 808, col: 1, offset: 19474},
 			expr: &notExpr{
-				pos: position{line: 370, col: 8, offset: 7756},
+				pos: position{line: 808, col: 8, offset: 19481},
 				expr: &anyMatcher{
-					line: 370, col: 9, offset: 7757,
+					line: 808, col: 9, offset: 19482,
 				},
 			},
 		},
 		{
 			name: "Constant",
-			pos:  position{line: 373, col: 1, offset: 7764},
+			pos:  position{line: 811, col: 1, offset: 19486},
 			expr: &actionExpr{
-				pos: position{line: 373, col: 13, offset: 7776},
+				pos: position{line: 811, col: 13, offset: 19498},
 				run: (*parser).callonConstant1,
 				expr: &choiceExpr{
-					pos: position{line: 373, col: 14, offset: 7777},
+					pos: position{line: 811, col: 14, offset: 19499},
 					alternatives: []any{
 						&seqExpr{
-							pos: position{line: 373, col: 14, offset: 7777},
+							pos: position{line: 811, col: 14, offset: 19499},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 373, col: 14, offset: 7777},
+									pos:        position{line: 811, col: 14, offset: 19499},
 									val:        "\"",
 									ignoreCase: false,
 									want:       "\"\\\"\"",
 								},
 								&zeroOrMoreExpr{
-									pos: position{line: 373, col: 18, offset: 7781},
+									pos: position{line: 811, col: 18, offset: 19503},
 									expr: &charClassMatcher{
-										pos:        position{line: 373, col: 18, offset: 7781},
+										pos:        position{line: 811, col: 18, offset: 19503},
 										val:        "[^\"]",
 										chars:      []rune{'"'},
 										ignoreCase: false,
@@ -1701,7 +3840,7 @@ var g = &grammar{
 									},
 								},
 								&litMatcher{
-									pos:        position{line: 373, col: 24, offset: 7787},
+									pos:        position{line: 811, col: 24, offset: 19509},
 									val:        "\"",
 									ignoreCase: false,
 									want:       "\"\\\"\"",
@@ -1709,21 +3848,21 @@ var g = &grammar{
 							},
 						},
 						&seqExpr{
-							pos: position{line: 373, col: 30, offset: 7793},
+							pos: position{line: 811, col: 30, offset: 19515},
 							exprs: []any{
 								&zeroOrOneExpr{
-									pos: position{line: 373, col: 30, offset: 7793},
+									pos: position{line: 811, col: 30, offset: 19515},
 									expr: &litMatcher{
-										pos:        position{line: 373, col: 30, offset: 7793},
+										pos:        position{line: 811, col: 30, offset: 19515},
 										val:        "-",
 										ignoreCase: false,
 										want:       "\"-\"",
 									},
 								},
 								&oneOrMoreExpr{
-									pos: position{line: 373, col: 35, offset: 7798},
+									pos: position{line: 811, col: 35, offset: 19520},
 									expr: &charClassMatcher{
-										pos:        position{line: 373, col: 35, offset: 7798},
+										pos:        position{line: 811, col: 35, offset: 19520},
 										val:        "[0-9]",
 										ranges:     []rune{'0', '9'},
 										ignoreCase: false,
@@ -1731,20 +3870,20 @@ var g = &grammar{
 									},
 								},
 								&zeroOrOneExpr{
-									pos: position{line: 373, col: 41, offset: 7804},
+									pos: position{line: 811, col: 41, offset: 19526},
 									expr: &seqExpr{
-										pos: position{line: 373, col: 42, offset: 7805},
+										pos: position{line: 811, col: 42, offset: 19527},
 										exprs: []any{
 											&litMatcher{
-												pos:        position{line: 373, col: 42, offset: 7805},
+												pos:        position{line: 811, col: 42, offset: 19527},
 												val:        ".",
 												ignoreCase: false,
 												want:       "\".\"",
 											},
 											&oneOrMoreExpr{
-												pos: position{line: 373, col: 46, offset: 7809},
+												pos: position{line: 811, col: 46, offset: 19531},
 												expr: &charClassMatcher{
-													pos:        position{line: 373, col: 46, offset: 7809},
+													pos:        position{line: 811, col: 46, offset: 19531},
 													val:        "[0-9]",
 													ranges:     []rune{'0', '9'},
 													ignoreCase: false,
@@ -1756,17 +3895,43 @@ var g = &grammar{
 								},
 							},
 						},
-						&litMatcher{
-							pos:        position{line: 373, col: 57, offset: 7820},
-							val:        "true",
-							ignoreCase: false,
-							want:       "\"true\"",
-						},
-						&litMatcher{
-							pos:        position{line: 373, col: 66, offset: 7829},
-							val:        "false",
-							ignoreCase: false,
-							want:       "\"false\"",
+						&seqExpr{
+							pos: position{line: 811, col: 57, offset: 19542},
+							exprs: []any{
+								&choiceExpr{
+									pos: position{line: 811, col: 58, offset: 19543},
+									alternatives: []any{
+										&litMatcher{
+											pos:        position{line: 811, col: 58, offset: 19543},
+											val:        "true",
+											ignoreCase: false,
+											want:       "\"true\"",
+										},
+										&litMatcher{
+											pos:        position{line: 811, col: 67, offset: 19552},
+											val:        "false",
+											ignoreCase: false,
+											want:       "\"false\"",
+										},
+										&litMatcher{
+											pos:        position{line: 811, col: 77, offset: 19562},
+											val:        "null",
+											ignoreCase: false,
+											want:       "\"null\"",
+										},
+									},
+								},
+								&notExpr{
+									pos: position{line: 811, col: 85, offset: 19570},
+									expr: &charClassMatcher{
+										pos:        position{line: 811, col: 86, offset: 19571},
+										val:        "[a-zA-Z0-9]",
+										ranges:     []rune{'a', 'z', 'A', 'Z', '0', '9'},
+										ignoreCase: false,
+										inverted:   false,
+									},
+								},
+							},
 						},
 					},
 				},
@@ -1776,7 +3941,6 @@ var g = &grammar{
 }
 
 func (c *current) onStart1(top any) (any, error) {
-
 	return top, nil
 }
 
@@ -1787,28 +3951,79 @@ func (p *parser) callonStart1() (any, error) {
 }
 
 func (c *current) onSeq1(v any) (any, error) {
+	return sequence(v)
+}
 
-	vals, ok := toAnySlice(v)
-	if !ok {
-		return nil, nil
-	} else {
-		left, _ := vals[0].(node)
-		right, _ := vals[1].(node)
+func (p *parser) callonSeq1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSeq1(stack["v"])
+}
 
-		left.setNext(right)
+func (c *current) onCaseSeq1(v any) (any, error) {
+	return sequence(v)
+}
 
-		return left, nil
+func (p *parser) callonCaseSeq1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onCaseSeq1(stack["v"])
+}
+
+func (c *current) onCaseItem1(i any) (any, error) {
+	return i, nil
+}
+
+func (p *parser) callonCaseItem1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onCaseItem1(stack["i"])
+}
+
+func (c *current) onComment1(lo, lc any) (any, error) {
+	node := &commentNode{baseNode: baseNode{child: nil}}
+	node.setTrims(lo.(bool), lc.(bool))
+	return node, nil
+}
+
+func (p *parser) callonComment1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onComment1(stack["lo"], stack["lc"])
+}
+
+func (c *current) onRaw1(lo, ic, t, eo, lc any) (any, error) {
+	node := &textNode{text: t.(string), baseNode: baseNode{child: nil}}
+
+	if ic.(bool) {
+		trimStart(node)
+	}
+
+	if eo.(bool) {
+		trimEnd(node)
 	}
+
+	node.setTrims(lo.(bool), lc.(bool))
+	return node, nil
 }
 
-func (p *parser) callonSeq1() (any, error) {
+func (p *parser) callonRaw1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onSeq1(stack["v"])
+	return p.cur.onRaw1(stack["lo"], stack["ic"], stack["t"], stack["eo"], stack["lc"])
 }
 
-func (c *current) onTextBlock1(t any) (any, error) {
+func (c *current) onRawText1() (any, error) {
+	return string(c.text), nil
+}
+
+func (p *parser) callonRawText1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onRawText1()
+}
 
+func (c *current) onTextBlock1(t any) (any, error) {
 	text := t.(string)
 	node := textNode{text: text, baseNode: baseNode{child: nil}}
 	return &node, nil
@@ -1820,31 +4035,78 @@ func (p *parser) callonTextBlock1() (any, error) {
 	return p.cur.onTextBlock1(stack["t"])
 }
 
-func (c *current) onAccessor1(e any) (any, error) {
-
+func (c *current) onAccessor1(lo, e, ps, lc any) (any, error) {
 	elem := e.(element)
-	return &accessNode{accessorElement: elem, baseNode: baseNode{child: nil}}, nil
+	stages, _ := toAnySlice(ps)
+
+	for _, stage := range stages {
+		stageElems, _ := toAnySlice(stage)
+		function := stageElems[3].(*userFunc)
+		elem = &userFunc{name: function.name, parameters: append([]element{elem}, function.parameters...)}
+	}
+
+	node := &accessNode{accessorElement: elem, baseNode: baseNode{child: nil}}
+	node.setTrims(lo.(bool), lc.(bool))
+	return node, nil
 }
 
 func (p *parser) callonAccessor1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onAccessor1(stack["e"])
+	return p.cur.onAccessor1(stack["lo"], stack["e"], stack["ps"], stack["lc"])
 }
 
-func (c *current) onElement1(e any) (any, error) {
+func (c *current) onElement1(cond, t any) (any, error) {
+	choice, _ := toAnySlice(t)
 
-	return e, nil
+	if choice == nil {
+		if single, isSingle := cond.(singleCondition); isSingle {
+			return single.element, nil
+		}
+
+		// an element is still returned on errors, so the rules using it can carry on parsing
+		err := fmt.Errorf("%s is a condition, which can only be used to choose between two elements (condition ? a : b)", strings.TrimSpace(string(c.text)))
+		return ternaryElement{condition: cond.(condition), whenTrue: constantElement{constant: "true"}, whenFalse: constantElement{constant: "false"}}, err
+	}
+
+	return ternaryElement{condition: cond.(condition), whenTrue: choice[4].(element), whenFalse: choice[8].(element)}, nil
 }
 
 func (p *parser) callonElement1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onElement1(stack["e"])
+	return p.cur.onElement1(stack["cond"], stack["t"])
 }
 
-func (c *current) onNonMathElement1(e any) (any, error) {
+func (c *current) onCoalesce1(first, rest any) (any, error) {
+	curr := first.(element)
+	restAny, _ := toAnySlice(rest)
+
+	for _, fallback := range restAny {
+		fallbackElems, _ := toAnySlice(fallback)
+		curr = coalesceElement{element: curr, fallback: fallbackElems[3].(element)}
+	}
+
+	return curr, nil
+}
+
+func (p *parser) callonCoalesce1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onCoalesce1(stack["first"], stack["rest"])
+}
+
+func (c *current) onOperand1(e any) (any, error) {
+	return e, nil
+}
+
+func (p *parser) callonOperand1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onOperand1(stack["e"])
+}
 
+func (c *current) onNonMathElement1(e any) (any, error) {
 	return e, nil
 }
 
@@ -1854,8 +4116,30 @@ func (p *parser) callonNonMathElement1() (any, error) {
 	return p.cur.onNonMathElement1(stack["e"])
 }
 
+func (c *current) onConcatenation1(first, rest any) (any, error) {
+	restAny, _ := toAnySlice(rest)
+
+	if restAny == nil {
+		return first, nil
+	}
+
+	parts := []element{first.(element)}
+
+	for _, part := range restAny {
+		partElems, _ := toAnySlice(part)
+		parts = append(parts, partElems[3].(element))
+	}
+
+	return concatenation{parts: parts}, nil
+}
+
+func (p *parser) callonConcatenation1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onConcatenation1(stack["first"], stack["rest"])
+}
+
 func (c *current) onExpression1(t, f any) (any, error) {
-
 	return expressionProcessor(t, f)
 }
 
@@ -1866,7 +4150,6 @@ func (p *parser) callonExpression1() (any, error) {
 }
 
 func (c *current) onTerm1(t, f any) (any, error) {
-
 	return expressionProcessor(t, f)
 }
 
@@ -1876,8 +4159,33 @@ func (p *parser) callonTerm1() (any, error) {
 	return p.cur.onTerm1(stack["t"], stack["f"])
 }
 
-func (c *current) onGroupedExpression1(e any) (any, error) {
+func (c *current) onUnary2(u any) (any, error) {
+	return negatedElement{element: u.(element)}, nil
+}
+
+func (p *parser) callonUnary2() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onUnary2(stack["u"])
+}
+
+func (c *current) onPower1(b, e any) (any, error) {
+	exponent, _ := toAnySlice(e)
+
+	if exponent == nil {
+		return b, nil
+	}
+
+	return expression{left: b.(element), right: exponent[3].(element), operation: power}, nil
+}
+
+func (p *parser) callonPower1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onPower1(stack["b"], stack["e"])
+}
 
+func (c *current) onGroupedExpression1(e any) (any, error) {
 	return e, nil
 }
 
@@ -1888,7 +4196,6 @@ func (p *parser) callonGroupedExpression1() (any, error) {
 }
 
 func (c *current) onAccessElement1() (any, error) {
-
 	text := string(c.text)
 	return accessElement{pattern: text}, nil
 
@@ -1900,45 +4207,57 @@ func (p *parser) callonAccessElement1() (any, error) {
 	return p.cur.onAccessElement1()
 }
 
-func (c *current) onIfElse1(cond, tr, os, el any) (any, error) {
-
+func (c *current) onIfElse1(lo, cond, ic, tr, os, el, eo, lc any) (any, error) {
 	other, _ := toAnySlice(os)
 	topCondition := cond.(condition)
-	topTrue := tr.(node)
+	topTrue, _ := tr.(node)
+
+	if ic.(bool) {
+		trimStart(topTrue)
+	}
 
 	top := &ifNode{condition: topCondition, trueClause: topTrue, baseNode: baseNode{child: nil}}
+	top.setTrims(lo.(bool), lc.(bool))
 	toRet := top
+	lastClause := topTrue
 
 	for _, elseClause := range other {
-
-		var elseTrue node
-		var elseCondition condition
 		elseClauseElems, _ := toAnySlice(elseClause)
+		elseCondition, _ := elseClauseElems[6].(condition)
+		elseTrue, _ := elseClauseElems[10].(node)
 
-		for _, elem := range elseClauseElems {
-			if elseCondition == nil {
-				elseCondition, _ = elem.(condition)
-			} else if elseTrue == nil {
-				elseTrue, _ = elem.(node)
-			}
+		if elseClauseElems[0].(bool) {
+			trimEnd(lastClause)
+		}
+
+		if elseClauseElems[8].(bool) {
+			trimStart(elseTrue)
 		}
 
 		elseNode := &ifNode{condition: elseCondition, trueClause: elseTrue, baseNode: baseNode{child: nil}}
 		top.falseClause = elseNode
 		top = elseNode
+		lastClause = elseTrue
 	}
 
 	if el != nil {
 		elems, _ := toAnySlice(el)
-		var falseClause node
+		falseClause, _ := elems[6].(node)
 
-		for _, e := range elems {
-			if falseClause == nil {
-				falseClause, _ = e.(node)
-			}
+		if elems[0].(bool) {
+			trimEnd(lastClause)
+		}
+
+		if elems[4].(bool) {
+			trimStart(falseClause)
 		}
 
 		top.falseClause = falseClause
+		lastClause = falseClause
+	}
+
+	if eo.(bool) {
+		trimEnd(lastClause)
 	}
 
 	return toRet, nil
@@ -1948,50 +4267,327 @@ func (c *current) onIfElse1(cond, tr, os, el any) (any, error) {
 func (p *parser) callonIfElse1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onIfElse1(stack["cond"], stack["tr"], stack["os"], stack["el"])
+	return p.cur.onIfElse1(stack["lo"], stack["cond"], stack["ic"], stack["tr"], stack["os"], stack["el"], stack["eo"], stack["lc"])
 }
 
-func (c *current) onIf1(cond, tr, f any) (any, error) {
-
+func (c *current) onIf1(lo, cond, ic, tr, f, eo, lc any) (any, error) {
 	condition := cond.(condition)
-	trueClause := tr.(node)
+	trueClause, _ := tr.(node)
 	var falseClause node
 
+	if ic.(bool) {
+		trimStart(trueClause)
+	}
+
+	lastClause := trueClause
+
 	if f != nil {
 		vals, _ := toAnySlice(f)
-		falseClause, _ = vals[4].(node)
+		falseClause, _ = vals[6].(node)
+
+		if vals[0].(bool) {
+			trimEnd(trueClause)
+		}
+
+		if vals[4].(bool) {
+			trimStart(falseClause)
+		}
+
+		lastClause = falseClause
+	}
+
+	if eo.(bool) {
+		trimEnd(lastClause)
 	}
 
 	node := ifNode{condition: condition, trueClause: trueClause, falseClause: falseClause, baseNode: baseNode{child: nil}}
+	node.setTrims(lo.(bool), lc.(bool))
 	return &node, nil
 }
 
 func (p *parser) callonIf1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onIf1(stack["cond"], stack["tr"], stack["f"])
+	return p.cur.onIf1(stack["lo"], stack["cond"], stack["ic"], stack["tr"], stack["f"], stack["eo"], stack["lc"])
 }
 
-func (c *current) onFor1(vars, t, p, l any) (any, error) {
-
+func (c *current) onFor1(lo, vars, t, p, nr, w, sb, li, ic, l, f, eo, lc any) (any, error) {
 	stringVars := vars.([]string)
-	loop := l.(node)
-	element := p.(element)
+	loop, _ := l.(node)
+	var elseClause node
+	var filter condition
+	var sortBy, limit element
+	descending := false
+
+	if where, hasWhere := toAnySlice(w); hasWhere {
+		filter = where[3].(condition)
+	}
+
+	if sort, hasSort := toAnySlice(sb); hasSort {
+		sortBy = sort[3].(element)
+		descending = sort[4] != nil
+	}
+
+	if lim, hasLimit := toAnySlice(li); hasLimit {
+		limit = lim[3].(element)
+	}
 
+	var to, step element
 	forType := string(t.([]byte))
 
-	foraa := forNode{forType: forType == "range", itemName: stringVars[1], indexName: stringVars[0], pattern: element, loop: loop, baseNode: baseNode{child: nil}}
+	if numeric, isNumeric := toAnySlice(nr); isNumeric {
+		if forType != "range" {
+			return nil, errors.New("Numeric ranges can only be used in range loops")
+		}
+
+		to = numeric[3].(element)
+
+		if stepElems, hasStep := toAnySlice(numeric[4]); hasStep {
+			step = stepElems[3].(element)
+		}
+	}
+
+	element := p.(element)
+
+	if ic.(bool) {
+		trimStart(loop)
+	}
+
+	lastClause := loop
+
+	if f != nil {
+		vals, _ := toAnySlice(f)
+		elseClause, _ = vals[5].(node)
+
+		if vals[0].(bool) {
+			trimEnd(loop)
+		}
+
+		if vals[4].(bool) {
+			trimStart(elseClause)
+		}
+
+		lastClause = elseClause
+	}
+
+	if eo.(bool) {
+		trimEnd(lastClause)
+	}
+
+	foraa := forNode{forType: forType == "range", itemName: stringVars[1], indexName: stringVars[0], pattern: element, filter: filter, sortBy: sortBy, descending: descending, limit: limit, to: to, step: step, loop: loop, elseClause: elseClause, baseNode: baseNode{child: nil}}
+	foraa.setTrims(lo.(bool), lc.(bool))
 	return &foraa, nil
 }
 
 func (p *parser) callonFor1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onFor1(stack["vars"], stack["t"], stack["p"], stack["l"])
+	return p.cur.onFor1(stack["lo"], stack["vars"], stack["t"], stack["p"], stack["nr"], stack["w"], stack["sb"], stack["li"], stack["ic"], stack["l"], stack["f"], stack["eo"], stack["lc"])
 }
 
-func (c *current) onForVars1(v1, v2 any) (any, error) {
+func (c *current) onLoopControl1(lo, k, w, lc any) (any, error) {
+	node := &loopControlNode{breaks: string(k.([]byte)) == "break", baseNode: baseNode{child: nil}}
+
+	if cond, hasCondition := toAnySlice(w); hasCondition {
+		node.condition = cond[3].(condition)
+	}
+
+	node.setTrims(lo.(bool), lc.(bool))
+	return node, nil
+}
+
+func (p *parser) callonLoopControl1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onLoopControl1(stack["lo"], stack["k"], stack["w"], stack["lc"])
+}
+
+func (c *current) onLet1(lo, n, e, lc any) (any, error) {
+	node := &letNode{name: n.(string), value: e.(element), baseNode: baseNode{child: nil}}
+	node.setTrims(lo.(bool), lc.(bool))
+	return node, nil
+}
+
+func (p *parser) callonLet1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onLet1(stack["lo"], stack["n"], stack["e"], stack["lc"])
+}
+
+func (c *current) onInclude1(lo, p, lc any) (any, error) {
+	path := p.(string)
+	top, err := includeTemplate(c, path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	node := &includeNode{path: path, top: top, baseNode: baseNode{child: nil}}
+	node.setTrims(lo.(bool), lc.(bool))
+	return node, nil
+}
+
+func (p *parser) callonInclude1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onInclude1(stack["lo"], stack["p"], stack["lc"])
+}
+
+func (c *current) onDefine1(lo, n, ps, ic, b, eo, lc any) (any, error) {
+	params, _ := ps.([]string)
+	body, _ := b.(node)
+
+	if ic.(bool) {
+		trimStart(body)
+	}
+
+	if eo.(bool) {
+		trimEnd(body)
+	}
+
+	node := &defineNode{name: n.(string), params: params, body: body, baseNode: baseNode{child: nil}}
+	node.setTrims(lo.(bool), lc.(bool))
+	return node, nil
+}
+
+func (p *parser) callonDefine1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onDefine1(stack["lo"], stack["n"], stack["ps"], stack["ic"], stack["b"], stack["eo"], stack["lc"])
+}
+
+func (c *current) onMacroParams1(first, rest any) (any, error) {
+	params := []string{first.(string)}
+	restAny, _ := toAnySlice(rest)
+
+	for _, param := range restAny {
+		paramElems, _ := toAnySlice(param)
+		params = append(params, paramElems[3].(string))
+	}
+
+	return params, nil
+}
+
+func (p *parser) callonMacroParams1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onMacroParams1(stack["first"], stack["rest"])
+}
+
+func (c *current) onExtends1(lo, p, lc any) (any, error) {
+	path := p.(string)
+	parent, err := includeTemplate(c, path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	node := &extendsNode{path: path, parent: parent, baseNode: baseNode{child: nil}}
+	node.setTrims(lo.(bool), lc.(bool))
+	return node, nil
+}
+
+func (p *parser) callonExtends1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onExtends1(stack["lo"], stack["p"], stack["lc"])
+}
+
+func (c *current) onBlock1(lo, n, ic, b, eo, lc any) (any, error) {
+	body, _ := b.(node)
+
+	if ic.(bool) {
+		trimStart(body)
+	}
+
+	if eo.(bool) {
+		trimEnd(body)
+	}
+
+	node := &blockNode{name: n.(string), body: body, baseNode: baseNode{child: nil}}
+	node.setTrims(lo.(bool), lc.(bool))
+	return node, nil
+}
+
+func (p *parser) callonBlock1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onBlock1(stack["lo"], stack["n"], stack["ic"], stack["b"], stack["eo"], stack["lc"])
+}
+
+func (c *current) onQuoted1() (any, error) {
+	text := string(c.text)
+	return text[1 : len(text)-1], nil
+}
+
+func (p *parser) callonQuoted1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onQuoted1()
+}
+
+func (c *current) onSwitch1(lo, s, cs, d, eo, lc any) (any, error) {
+	casesAny, _ := toAnySlice(cs)
+	cases := []switchCase{}
+	var lastClause node
+
+	for _, caseAny := range casesAny {
+		caseElems, _ := toAnySlice(caseAny)
+		values := []element{caseElems[4].(element)}
+		restAny, _ := toAnySlice(caseElems[5])
+
+		for _, value := range restAny {
+			valueElems, _ := toAnySlice(value)
+			values = append(values, valueElems[3].(element))
+		}
+
+		body, _ := caseElems[8].(node)
+
+		if caseElems[0].(bool) {
+			trimEnd(lastClause)
+		}
+
+		if caseElems[7].(bool) {
+			trimStart(body)
+		}
+
+		cases = append(cases, switchCase{values: values, body: body})
+		lastClause = body
+	}
+
+	var defaultClause node
+
+	if d != nil {
+		defaultElems, _ := toAnySlice(d)
+		defaultClause, _ = defaultElems[5].(node)
 
+		if defaultElems[0].(bool) {
+			trimEnd(lastClause)
+		}
+
+		if defaultElems[4].(bool) {
+			trimStart(defaultClause)
+		}
+
+		lastClause = defaultClause
+	}
+
+	if eo.(bool) {
+		trimEnd(lastClause)
+	}
+
+	node := &switchNode{subject: s.(element), cases: cases, defaultClause: defaultClause, baseNode: baseNode{child: nil}}
+	node.setTrims(lo.(bool), lc.(bool))
+	return node, nil
+}
+
+func (p *parser) callonSwitch1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSwitch1(stack["lo"], stack["s"], stack["cs"], stack["d"], stack["eo"], stack["lc"])
+}
+
+func (c *current) onForVars1(v1, v2 any) (any, error) {
 	vstr1, _ := v1.(string)
 	vstr2, _ := v2.(string)
 	return []string{vstr1, vstr2}, nil
@@ -2003,8 +4599,17 @@ func (p *parser) callonForVars1() (any, error) {
 	return p.cur.onForVars1(stack["v1"], stack["v2"])
 }
 
-func (c *current) onVarName1() (any, error) {
+func (c *current) onLoopVar3() (any, error) {
+	return "_", nil
+}
+
+func (p *parser) callonLoopVar3() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onLoopVar3()
+}
 
+func (c *current) onVarName1() (any, error) {
 	varName := string(c.text)
 	return varName, nil
 }
@@ -2019,15 +4624,21 @@ func (c *current) onUserFunction1(n, p any) (any, error) {
 
 	name, _ := n.(string)
 	paramsAny, _ := toAnySlice(p)
-	firstParam, _ := paramsAny[0].(element)
-	restAny, _ := toAnySlice(paramsAny[1])
+	functionParams := []element{}
 
-	functionParams := []element{firstParam}
+	// functions may be called without parameters
+	if paramsAny != nil {
+		firstParam, _ := paramsAny[0].(element)
+		restAny, _ := toAnySlice(paramsAny[1])
 
-	for _, param := range restAny {
-		paramNode, isNode := param.(element)
-		if isNode {
-			functionParams = append(functionParams, paramNode)
+		functionParams = append(functionParams, firstParam)
+
+		for _, param := range restAny {
+			paramElems, _ := toAnySlice(param)
+			paramNode, isNode := paramElems[3].(element)
+			if isNode {
+				functionParams = append(functionParams, paramNode)
+			}
 		}
 	}
 
@@ -2040,8 +4651,21 @@ func (p *parser) callonUserFunction1() (any, error) {
 	return p.cur.onUserFunction1(stack["n"], stack["p"])
 }
 
-func (c *current) onOrCondition1(base, os any) (any, error) {
+func (c *current) onPipeStage1(f any) (any, error) {
+	if name, isName := f.(string); isName {
+		return &userFunc{name: name, parameters: []element{}}, nil
+	}
+
+	return f, nil
+}
+
+func (p *parser) callonPipeStage1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onPipeStage1(stack["f"])
+}
 
+func (c *current) onOrCondition1(base, os any) (any, error) {
 	baseCondition, _ := base.(condition)
 	others, _ := toAnySlice(os)
 
@@ -2069,7 +4693,6 @@ func (p *parser) callonOrCondition1() (any, error) {
 }
 
 func (c *current) onAndCondition1(base, os any) (any, error) {
-
 	baseCondition, _ := base.(condition)
 	others, _ := toAnySlice(os)
 
@@ -2121,22 +4744,80 @@ func (p *parser) callonCondition1() (any, error) {
 	return p.cur.onCondition1(stack["e"])
 }
 
-func (c *current) onOfType1(el, e any) (any, error) {
+func (c *current) onElementCondition1(el, t any) (any, error) {
+	elem := el.(element)
+	rest, _ := toAnySlice(t)
+
+	if rest == nil {
+		return singleCondition{element: elem}, nil
+	}
+
+	switch cond := rest[1].(type) {
+	case ofType:
+		cond.element = elem
+		return cond, nil
+	case matchesCondition:
+		cond.element = elem
+		return cond, nil
+	case operatorCondition:
+		cond.left = elem
+		return cond, nil
+	}
+
+	return nil, errors.New("Invalid condition")
+}
+
+func (p *parser) callonElementCondition1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onElementCondition1(stack["el"], stack["t"])
+}
 
+func (c *current) onOfType1(e any) (any, error) {
 	elemType, _ := e.([]byte)
-	strType := string(elemType)
-	elem, _ := el.(element)
-	return ofType{element: elem, typeOf: convertType(strType)}, nil
+	return ofType{typeOf: convertType(string(elemType))}, nil
 }
 
 func (p *parser) callonOfType1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onOfType1(stack["el"], stack["e"])
+	return p.cur.onOfType1(stack["e"])
 }
 
-func (c *current) onExists1(p any) (any, error) {
+func (c *current) onMatches1(r any) (any, error) {
+	regex, err := regexp.Compile(r.(string))
+
+	// the condition is still returned on errors, so the rules using it can carry on parsing
+	return matchesCondition{regex: regex}, err
+}
+
+func (p *parser) callonMatches1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onMatches1(stack["r"])
+}
+
+func (c *current) onComparison1(o, r any) (any, error) {
+	return operatorCondition{right: r.(element), operator: convertOperator(o.(string))}, nil
+}
+
+func (p *parser) callonComparison1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onComparison1(stack["o"], stack["r"])
+}
+
+func (c *current) onNegatedElement1(e any) (any, error) {
+	return negatedCondition{toNegate: singleCondition{element: e.(element)}}, nil
+}
+
+func (p *parser) callonNegatedElement1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onNegatedElement1(stack["e"])
+}
 
+func (c *current) onExists1(p any) (any, error) {
 	element, _ := p.(element)
 	return existsCondition{element: element}, nil
 }
@@ -2148,7 +4829,6 @@ func (p *parser) callonExists1() (any, error) {
 }
 
 func (c *current) onGroupedCondition1(cond any) (any, error) {
-
 	return cond, nil
 }
 
@@ -2158,50 +4838,18 @@ func (p *parser) callonGroupedCondition1() (any, error) {
 	return p.cur.onGroupedCondition1(stack["cond"])
 }
 
-func (c *current) onFromElements1(e any) (any, error) {
-
-	elems, _ := toAnySlice(e)
-
-	isOperation := len(elems) == 5
-
-	if isOperation {
-		left, _ := elems[0].(element)
-		right, _ := elems[4].(element)
-		operator, _ := elems[2].([]uint8)
-		return operatorCondition{left: left, right: right, operator: convertOperator(string(operator))}, nil
-	}
-
-	isElem := len(elems) == 2
-
-	if isElem {
-		elem, _ := elems[1].(element)
-		elemCondition := singleCondition{element: elem}
-		if elems[0] == nil {
-			return elemCondition, nil
-		} else {
-			return negatedCondition{toNegate: elemCondition}, nil
-		}
-
-	}
-
-	condition, isCondition := e.(condition)
-
-	if isCondition {
-		return condition, nil
-	}
-
-	return nil, errors.New("Invalid condition")
+func (c *current) onOperator1() (any, error) {
+	return string(c.text), nil
 }
 
-func (p *parser) callonFromElements1() (any, error) {
+func (p *parser) callonOperator1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onFromElements1(stack["e"])
+	return p.cur.onOperator1()
 }
 
 func (c *current) onText1() (any, error) {
-
-	text := string(c.text)
+	text := strings.ReplaceAll(string(c.text), "$$", "$")
 	return text, nil
 }
 
@@ -2211,6 +4859,26 @@ func (p *parser) callonText1() (any, error) {
 	return p.cur.onText1()
 }
 
+func (c *current) onOpen1(t any) (any, error) {
+	return t != nil, nil
+}
+
+func (p *parser) callonOpen1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onOpen1(stack["t"])
+}
+
+func (c *current) onClose1(t any) (any, error) {
+	return t != nil, nil
+}
+
+func (p *parser) callonClose1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onClose1(stack["t"])
+}
+
 func (c *current) onConstant1() (any, error) {
 
 	text := string(c.text)
@@ -2238,7 +4906,7 @@ var (
 
 	// errMaxExprCnt is used to signal that the maximum number of
 	// expressions have been parsed.
-	errMaxExprCnt = errors.New("max number of expresssions parsed")
+	errMaxExprCnt = errors.New("max number of expressions parsed")
 )
 
 // Option is a function that can set an option on the parser. It returns
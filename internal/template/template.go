@@ -4,7 +4,11 @@
 package parser
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
 
 	"github.com/robertkrimen/otto"
 )
@@ -26,6 +30,45 @@ type Getter func(data []byte, pattern string) (string, ElementType, error)
 // Should return an error if data is not an array
 type ArrayEach func(data []byte, forEach func(curr []byte, dataType ElementType)) error
 
+// shadow returns a Getter which resolves name to the given value (and paths within it, such as
+// name->sub or name[0], to the matching values inside it), while every other pattern is still
+// fetched by getter. It is how loops and bindings make their names visible to inner nodes.
+func shadow(getter Getter, name string, value string, valueType ElementType) Getter {
+	return func(data []byte, pattern string) (string, ElementType, error) {
+		if pattern == name {
+			return value, valueType, nil
+		}
+
+		rest, usesName := strings.CutPrefix(pattern, name)
+
+		if usesName && (strings.HasPrefix(rest, "->") || strings.HasPrefix(rest, "[")) {
+			return getter([]byte(value), rest)
+		}
+
+		return getter(data, pattern)
+	}
+}
+
+// shadowElement returns a Getter which resolves name (and paths within it) to the value of elem, evaluated in
+// the context elemCtx whenever the name is used, while every other pattern is still fetched by getter.
+func shadowElement(getter Getter, name string, elem element, elemCtx *ASTContext) Getter {
+	return func(data []byte, pattern string) (string, ElementType, error) {
+		rest, usesName := strings.CutPrefix(pattern, name)
+
+		if !usesName || (rest != "" && !strings.HasPrefix(rest, "->") && !strings.HasPrefix(rest, "[")) {
+			return getter(data, pattern)
+		}
+
+		value, valueType, err := elementText(elem, elemCtx)
+
+		if err != nil || rest == "" {
+			return value, valueType, err
+		}
+
+		return getter([]byte(value), rest)
+	}
+}
+
 // ObjectEach defines how to iterate over the properties of an key:value object
 // data is the bytes of the object
 // forEach is the function that is execute for each property of the pair, where prop is the name of the property, val
@@ -52,28 +95,169 @@ type ASTContext struct {
 
 	// Data is the data where variables to insert into the template exist
 	Data []byte
+
+	// macros are the macros defined in the template which can be invoked, by name
+	macros map[string]*defineNode
+
+	// blocks are the blocks of templates extending the one being evaluated, which override
+	// its own blocks with the same name
+	blocks map[string]*blockNode
+
+	// loop is the metadata of the innermost loop being evaluated, as a JSON object
+	loop json.RawMessage
+}
+
+// withGetter returns a copy of the context where variables are fetched by getter
+func (ctx *ASTContext) withGetter(getter Getter) *ASTContext {
+	scoped := *ctx
+	scoped.Getter = getter
+	return &scoped
+}
+
+// partialsKey is the key of the global store where the partials of a parse are kept
+const partialsKey = "partials"
+
+// templateKey is the key of the global store where the path of the template being parsed is kept
+const templateKey = "template"
+
+// partials keeps track of the templates parsed while parsing another (through includes),
+// so that each of them is parsed only once and cyclic includes are detected.
+type partials struct {
+	// parsed are the templates which have already been parsed, by path
+	parsed map[string]node
+
+	// parsing are the templates currently being parsed, by path
+	parsing map[string]bool
+
+	// parseFile parses a single file, kept here as the parser itself refers to partials
+	parseFile func(filename string, opts ...Option) (any, error)
+}
+
+// newPartials returns an empty set of partials
+func newPartials() *partials {
+	return &partials{parsed: make(map[string]node), parsing: make(map[string]bool), parseFile: ParseFile}
+}
+
+// parse parses the template in path, returning its top node (nil for an empty template).
+// Returns an error if the template is already being parsed, meaning it includes itself.
+func (p *partials) parse(path string) (node, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if top, ok := p.parsed[path]; ok {
+		return top, nil
+	}
+
+	if p.parsing[path] {
+		return nil, fmt.Errorf("cyclic include of %s", path)
+	}
+
+	p.parsing[path] = true
+	defer delete(p.parsing, path)
+
+	top, err := p.parseFile(path, GlobalStore(partialsKey, p), GlobalStore(templateKey, path))
+	if err != nil {
+		return nil, err
+	}
+
+	var actual node
+
+	if top != nil {
+		// a nil top means the template has no content
+		var ok bool
+		actual, ok = top.(node)
+		if !ok {
+			return nil, errors.New("Incorrect syntax somewhere") // Not great, but this error should not happen.
+		}
+	}
+
+	actual = inherit(actual)
+	p.parsed[path] = actual
+	return actual, nil
+}
+
+// inherit checks whether the template starting at top extends another one. If so, the template is replaced by
+// its extends node, which keeps the blocks defined at the top level of the template to override the ones in the
+// parent, as well as its top level lets and defines. Everything else in an extending template is ignored.
+func inherit(top node) node {
+	var extends *extendsNode
+	blocks := make(map[string]*blockNode)
+	scopes := []scoper{}
+
+	for n := top; n != nil; n = n.next() {
+		switch typed := n.(type) {
+		case *extendsNode:
+			if extends == nil {
+				extends = typed
+			}
+		case *blockNode:
+			blocks[typed.name] = typed
+		case *letNode, *defineNode:
+			scopes = append(scopes, typed.(scoper))
+		}
+	}
+
+	if extends == nil {
+		return top
+	}
+
+	extends.blocks = blocks
+	extends.scopes = scopes
+	extends.setNext(nil)
+	return extends
+}
+
+// withMacro returns a copy of the context where macro can be invoked
+func (ctx *ASTContext) withMacro(macro *defineNode) *ASTContext {
+	scoped := *ctx
+	scoped.macros = make(map[string]*defineNode, len(ctx.macros)+1)
+
+	for name, other := range ctx.macros {
+		scoped.macros[name] = other
+	}
+
+	scoped.macros[macro.name] = macro
+	return &scoped
+}
+
+// withBlocks returns a copy of the context where blocks override the blocks of the template being evaluated.
+// Blocks already in the context come from templates further down the inheritance, so they keep priority.
+func (ctx *ASTContext) withBlocks(blocks map[string]*blockNode) *ASTContext {
+	scoped := *ctx
+	scoped.blocks = make(map[string]*blockNode, len(blocks)+len(ctx.blocks))
+
+	for name, block := range blocks {
+		scoped.blocks[name] = block
+	}
+
+	for name, block := range ctx.blocks {
+		scoped.blocks[name] = block
+	}
+
+	return &scoped
 }
 
 // ParseTemplate takes a filename and parses the template into a Template struct
 // if an error happens in parsing, it is returned.
 func ParseTemplate(templateName string) (*Template, error) {
-	top, err := ParseFile(templateName)
+	top, err := newPartials().parse(templateName)
 	if err != nil {
 		return nil, err
 	}
 
-	actual, ok := top.(node)
-	if !ok {
-		return nil, errors.New("Incorrect syntax somewhere") // Not great, but this error should not happen.
-	}
-
-	return &Template{top: actual}, nil
+	return &Template{top: top}, nil
 }
 
 // ApplyTemplate takes a context and a parsed template and performs the necessary replacements.
 // The functions defined in ctx will be used to replace where needed sections of the parsed template.
 // It returns the string of the final template - with all the replacements performed, or
 func ApplyTemplate(template *Template, ctx *ASTContext) (string, error) {
+	if template.top == nil {
+		return "", nil
+	}
+
 	s, err := template.top.evaluate(ctx)
 	return s, err
 }
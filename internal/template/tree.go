@@ -1,6 +1,11 @@
 package parser
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -13,6 +18,8 @@ type node interface {
 	evaluate(ctx *ASTContext) (string, error)
 	next() node
 	setNext(n node)
+	trims() (bool, bool)
+	setTrims(before, after bool)
 }
 
 // baseNode serves to provide the next functionality to all nodes, due to
@@ -20,6 +27,14 @@ type node interface {
 type baseNode struct {
 	// child is the node to evaluate next
 	child node
+
+	// trimBefore is set when the opening tag of the node has a trim marker ($-),
+	// removing the whitespace at the end of the text that precedes it
+	trimBefore bool
+
+	// trimAfter is set when the closing tag of the node has a trim marker (-$),
+	// removing the whitespace at the start of the text that follows it
+	trimAfter bool
 }
 
 // returns the next node to evaluate
@@ -32,15 +47,34 @@ func (b *baseNode) setNext(n node) {
 	b.child = n
 }
 
+// trims returns whether the whitespace before and after the node should be removed
+func (b *baseNode) trims() (bool, bool) {
+	return b.trimBefore, b.trimAfter
+}
+
+// setTrims sets whether the whitespace before and after the node should be removed
+func (b *baseNode) setTrims(before, after bool) {
+	b.trimBefore = before
+	b.trimAfter = after
+}
+
 // withChild receives a string and if the node has a next, evaluates it and
 // concatenates the two strings. This proceess is common across all nodes -
 // they evaluate themselves, and then the next one, returning the concatenation.
+// If the next nodes break or continue a loop, the string is kept in the signal.
 func (b *baseNode) withChild(def string, ctx *ASTContext) (string, error) {
 
 	if b.child == nil {
 		return def, nil
 	} else {
 		childText, err := b.child.evaluate(ctx)
+
+		var signal *loopSignal
+		if errors.As(err, &signal) {
+			signal.text = def + signal.text
+			return "", signal
+		}
+
 		if err != nil {
 			return "", err
 		}
@@ -48,6 +82,61 @@ func (b *baseNode) withChild(def string, ctx *ASTContext) (string, error) {
 	}
 }
 
+// evaluateClause evaluates a clause of a flow node (such as the body of an if or a for).
+// Clauses may be empty (or contain only comments), in which case they evaluate to nothing.
+func evaluateClause(clause node, ctx *ASTContext) (string, error) {
+	if clause == nil {
+		return "", nil
+	}
+	return clause.evaluate(ctx)
+}
+
+// whitespace are the characters removed by trim markers
+const whitespace = " \t\r\n"
+
+// trimStart removes the leading whitespace of a sequence of nodes, if it starts with text.
+// Comments write nothing, so the text after them is trimmed instead.
+func trimStart(n node) {
+	if _, isComment := n.(*commentNode); isComment {
+		trimStart(n.next())
+	}
+
+	if text, ok := n.(*textNode); ok {
+		text.text = strings.TrimLeft(text.text, whitespace)
+	}
+}
+
+// trimsBefore returns whether the whitespace before a sequence of nodes should be removed, by its first node
+// or, since comments write nothing, by the first node after the comments it starts with
+func trimsBefore(n node) bool {
+	for ; n != nil; n = n.next() {
+		if before, _ := n.trims(); before {
+			return true
+		}
+
+		if _, isComment := n.(*commentNode); !isComment {
+			return false
+		}
+	}
+
+	return false
+}
+
+// trimEnd removes the trailing whitespace of a sequence of nodes, if it ends with text (ignoring comments)
+func trimEnd(n node) {
+	var last node
+
+	for ; n != nil; n = n.next() {
+		if _, isComment := n.(*commentNode); !isComment {
+			last = n
+		}
+	}
+
+	if text, ok := last.(*textNode); ok {
+		text.text = strings.TrimRight(text.text, whitespace)
+	}
+}
+
 // textNode is a node which represents plain text, to be printed in the same way
 // as it was in the provided template
 type textNode struct {
@@ -67,6 +156,16 @@ func (n *textNode) evaluate(ctx *ASTContext) (string, error) {
 	return result, err
 }
 
+// commentNode is a comment of the template, which writes nothing. It is kept in the tree for its trim markers.
+type commentNode struct {
+	baseNode
+}
+
+// evaluate of a commentNode returns only the evaluated text of its proceeding nodes
+func (n *commentNode) evaluate(ctx *ASTContext) (string, error) {
+	return n.withChild("", ctx)
+}
+
 // accessNode represents the access to a variable - be it a function, data access or a constant
 type accessNode struct {
 	baseNode
@@ -125,10 +224,10 @@ func (n *ifNode) evaluate(ctx *ASTContext) (string, error) {
 	var clause string
 
 	if result {
-		clause, err = n.trueClause.evaluate(ctx)
+		clause, err = evaluateClause(n.trueClause, ctx)
 
-	} else if n.falseClause != nil {
-		clause, err = n.falseClause.evaluate(ctx)
+	} else {
+		clause, err = evaluateClause(n.falseClause, ctx)
 	}
 
 	if err != nil {
@@ -142,118 +241,651 @@ func (n *ifNode) evaluate(ctx *ASTContext) (string, error) {
 
 // forNode represents the execution of a loop in the template. It always has two associated variables,
 // which vary depending on the type of loop. It is an iterative loop, which evaluates its child for every
-// member of the element it is iterating
+// member of the element it is iterating, or its else clause if there are none
 type forNode struct {
 	baseNode
-	itemName  string
-	indexName string
-	pattern   element
-	loop      node
-	forType   bool
+	itemName   string
+	indexName  string
+	pattern    element
+	loop       node
+	elseClause node
+	forType    bool
+
+	// filter selects the members to iterate, if set
+	filter condition
+
+	// sortBy is the element members are sorted by, if set
+	sortBy element
+
+	// descending sorts the members in descending order
+	descending bool
+
+	// limit is the maximum number of members to iterate, if set
+	limit element
+
+	// to is the last number of a numeric range, in which case pattern is the first
+	to element
+
+	// step is the difference between the numbers of a numeric range, if set
+	step element
 }
 
-// rangeFor is the execution of a for node according to the iteration of array (represented by a slice of bytes)
-// it returns the evaluation of the child node for each element of the array
-func (n *forNode) rangeFor(ctx *ASTContext, array []byte) string {
-	sb := strings.Builder{}
-	i := 1
+// loopName is the name the metadata of the innermost loop is accessed with
+const loopName = "loop"
+
+// loopItem is a member of the element a forNode iterates, along with its index (or property name)
+type loopItem struct {
+	key      string
+	keyType  ElementType
+	value    string
+	elemType ElementType
+}
+
+// loopMetadata describes an iteration of a loop, and is accessible inside it as an object named loop
+type loopMetadata struct {
+	// Index of the item, starting at 1
+	Index int `json:"index"`
+
+	// Index0 is the index of the item, starting at 0
+	Index0 int `json:"index0"`
+
+	// First is set on the first item
+	First bool `json:"first"`
+
+	// Last is set on the last item
+	Last bool `json:"last"`
+
+	// Length is the number of items
+	Length int `json:"length"`
+
+	// Parent is the metadata of the enclosing loop, if there is one
+	Parent json.RawMessage `json:"parent,omitempty"`
+}
+
+// rangeItems returns the items of an array (represented by a slice of bytes), indexed from 1
+func (n *forNode) rangeItems(ctx *ASTContext, array []byte) []loopItem {
+	items := []loopItem{}
 
 	forEach := func(curr []byte, dataType ElementType) {
+		index := strconv.Itoa(len(items) + 1)
+		items = append(items, loopItem{key: index, keyType: Number, value: string(curr), elemType: dataType})
+	}
+
+	ctx.ArrayEach(array, forEach)
+	return items
+}
+
+// propItems returns the properties of an object (represented by a slice of bytes), with their names as keys
+func (n *forNode) propItems(ctx *ASTContext, object []byte) []loopItem {
+	items := []loopItem{}
+
+	forEach := func(prop string, val []byte, dataType ElementType) {
+		items = append(items, loopItem{key: prop, keyType: String, value: string(val), elemType: dataType})
+	}
+
+	ctx.ObjectEach(object, forEach)
+	return items
+}
+
+// itemGetter returns a Getter where the item and its key are accessible by their names
+func (n *forNode) itemGetter(getter Getter, item loopItem) Getter {
+	newGetter := shadow(getter, n.itemName, item.value, item.elemType)
+	return shadow(newGetter, n.indexName, item.key, item.keyType)
+}
 
-		newGetter := func(data []byte, pattern string) (string, ElementType, error) {
+// selectItems keeps the items matching the filter, sorted and limited as defined by the loop.
+// The filter and the element items are sorted by are evaluated with the item and its key accessible by their names.
+func (n *forNode) selectItems(ctx *ASTContext, items []loopItem) ([]loopItem, error) {
+	selected := []loopItem{}
+	sortKeys := []item{}
 
-			if pattern == n.indexName {
-				return strconv.Itoa(i), Number, nil
+	for _, it := range items {
+		scoped := ctx.withGetter(n.itemGetter(ctx.Getter, it))
+
+		if n.filter != nil {
+			matches, err := n.filter.eval(scoped)
+
+			if err != nil {
+				return nil, err
 			}
 
-			updated, usesItem := strings.CutPrefix(pattern, n.itemName)
-
-			if usesItem {
-				if updated != "" {
-					g, t, err := ctx.Getter(curr, updated)
-					return g, t, err
-				} else {
-					return string(curr), dataType, nil
-				}
-			} else {
-				return ctx.Getter(data, pattern)
+			if !matches {
+				continue
 			}
+		}
 
+		if n.sortBy != nil {
+			v, tpe, err := n.sortBy.value(scoped)
+
+			if err != nil {
+				return nil, err
+			}
+
+			sortKeys = append(sortKeys, item{value: v, elemType: tpe})
 		}
 
-		s, err := n.loop.evaluate(&ASTContext{Data: ctx.Data, Getter: newGetter, ArrayEach: ctx.ArrayEach, ObjectEach: ctx.ObjectEach})
-		i++
+		selected = append(selected, it)
+	}
+
+	if n.sortBy != nil {
+		order := make([]int, len(selected))
+
+		for i := range order {
+			order[i] = i
+		}
+
+		var sortErr error
+
+		slices.SortStableFunc(order, func(a, b int) int {
+			r, err := compareItems(sortKeys[a], sortKeys[b])
+
+			if err != nil {
+				sortErr = err
+			}
+
+			if n.descending {
+				return -int(r)
+			}
+			return int(r)
+		})
+
+		if sortErr != nil {
+			return nil, sortErr
+		}
+
+		sorted := make([]loopItem, len(selected))
+
+		for i, index := range order {
+			sorted[i] = selected[index]
+		}
+
+		selected = sorted
+	}
+
+	if n.limit != nil {
+		v, tpe, err := n.limit.value(ctx)
 
 		if err != nil {
-			panic(err.Error())
+			return nil, err
 		}
 
-		sb.WriteString(s)
+		limit, err := intArg("limit", []item{{value: v, elemType: tpe}}, 0)
+
+		if err != nil || limit < 0 {
+			return nil, errors.New("The limit of a loop must be a whole number, zero or above")
+		}
+
+		selected = selected[:min(limit, len(selected))]
 	}
 
-	ctx.ArrayEach(array, forEach)
-	return sb.String()
+	return selected, nil
 }
 
-// propFor is the execution of a forNode in the context of property iteration of an object.
-// It evaluates the loop node for each property of the given object and returns the result.
-func (n *forNode) propFor(ctx *ASTContext, object []byte) string {
+// iterate evaluates the loop node for each item, where the item, its key and the loop metadata are
+// accessible by their names, and returns the concatenation
+func (n *forNode) iterate(ctx *ASTContext, items []loopItem) (string, error) {
 	sb := strings.Builder{}
 
-	forEach := func(prop string, val []byte, dataType ElementType) {
+	for i, item := range items {
+		metadata, err := json.Marshal(loopMetadata{
+			Index:  i + 1,
+			Index0: i,
+			First:  i == 0,
+			Last:   i == len(items)-1,
+			Length: len(items),
+			Parent: ctx.loop,
+		})
+
+		if err != nil {
+			return "", err
+		}
 
-		newGetter := func(data []byte, pattern string) (string, ElementType, error) {
+		newGetter := n.itemGetter(shadow(ctx.Getter, loopName, string(metadata), Object), item)
 
-			if pattern == n.indexName {
-				return prop, String, nil
-			}
+		scoped := ctx.withGetter(newGetter)
+		scoped.loop = metadata
+
+		s, err := evaluateClause(n.loop, scoped)
 
-			updated, usesItem := strings.CutPrefix(pattern, n.itemName)
+		var signal *loopSignal
+		if errors.As(err, &signal) {
+			sb.WriteString(signal.text)
 
-			if usesItem {
-				if updated != "" {
-					return ctx.Getter(val, updated)
-				} else {
-					return string(val), dataType, nil
-				}
-			} else {
-				return ctx.Getter(data, pattern)
+			if signal.breaks {
+				break
 			}
+			continue
+		}
 
+		if err != nil {
+			return "", err
 		}
 
-		s, err := n.loop.evaluate(&ASTContext{Data: ctx.Data, Getter: newGetter, ArrayEach: ctx.ArrayEach, ObjectEach: ctx.ObjectEach})
+		sb.WriteString(s)
+	}
+
+	return sb.String(), nil
+}
+
+// loopSignal is returned as the error of a break or continue, so that it goes up through the nodes
+// evaluated in an iteration until it reaches the loop. It keeps the text evaluated in the iteration before it.
+type loopSignal struct {
+	// breaks is set for breaks, which stop the loop, and unset for continues, which skip to the next item
+	breaks bool
+
+	// text evaluated in the iteration before the signal
+	text string
+}
+
+// Error is the error of a signal which does not reach a loop
+func (s *loopSignal) Error() string {
+	return "break and continue can only be used inside loops"
+}
+
+// loopControlNode represents a break or a continue of the enclosing loop, which may depend on a condition
+type loopControlNode struct {
+	baseNode
+
+	// breaks is set for breaks and unset for continues
+	breaks bool
+
+	// condition of the break or continue, if set
+	condition condition
+}
+
+// evaluate on a loopControlNode returns the signal to the enclosing loop, if there is no condition or it is true.
+// Otherwise, it evaluates the nodes following it.
+func (n *loopControlNode) evaluate(ctx *ASTContext) (string, error) {
+	logger.DefaultLogger.Node("Loop control, break:", n.breaks)
+
+	if n.condition != nil {
+		result, err := n.condition.eval(ctx)
 
 		if err != nil {
-			panic(err.Error())
+			return "", err
 		}
 
-		sb.WriteString(s)
+		if !result {
+			return n.withChild("", ctx)
+		}
 	}
 
-	ctx.ObjectEach(object, forEach)
-	return sb.String()
+	return "", &loopSignal{breaks: n.breaks}
+}
+
+// collectionItems returns the items of the array or object the loop iterates. If it does not exist and the
+// loop has an else clause, there are no items.
+func (n *forNode) collectionItems(ctx *ASTContext) ([]loopItem, error) {
+	a, err := n.pattern.stringValue(ctx)
+
+	if err != nil && n.elseClause == nil {
+		return nil, err
+	}
+
+	iterable := []byte(a)
+
+	if err != nil {
+		return []loopItem{}, nil
+	} else if n.forType {
+		return n.rangeItems(ctx, iterable), nil
+	}
+
+	return n.propItems(ctx, iterable), nil
+}
+
+// maxRangeLength is the largest amount of numbers a numeric range can have, as they are all generated
+// before the loop starts
+const maxRangeLength = 100000
+
+// numberItems returns the numbers of a numeric range, from the pattern to the to element (inclusive) in steps of
+// the step element. Without a step, ranges go up or down by 1. Returns an error if the step is zero.
+func (n *forNode) numberItems(ctx *ASTContext) ([]loopItem, error) {
+	from, err := getNumber(n.pattern, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := getNumber(n.to, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	step := 1.0
+
+	if n.step != nil {
+		step, err = getNumber(n.step, ctx)
+		if err != nil {
+			return nil, err
+		}
+	} else if from > to {
+		step = -1
+	}
+
+	if step == 0 {
+		return nil, errors.New("The step of a range cannot be zero")
+	}
+
+	// the number of steps is rounded when it is almost whole, so that floating point errors in decimal
+	// steps (such as 0..0.3 step 0.1) do not leave out the last number
+	steps := (to - from) / step
+
+	if rounded := math.Round(steps); math.Abs(steps-rounded) < 1e-9*math.Max(1, math.Abs(rounded)) {
+		steps = rounded
+	}
+
+	if !(steps < maxRangeLength) {
+		return nil, fmt.Errorf("Ranges can have at most %d numbers", maxRangeLength)
+	}
+
+	count := int(math.Floor(steps)) + 1
+	items := make([]loopItem, 0, max(count, 0))
+
+	// each number is computed from the start, instead of adding up steps, so their errors do not accumulate.
+	// Numbers are written with 15 significant digits, which hides the errors left (0.30000000000000004 is 0.3).
+	for i := 0; i < count; i++ {
+		number := strconv.FormatFloat(from+float64(i)*step, 'g', 15, 64)
+		fl, _ := strconv.ParseFloat(number, 64)
+		items = append(items, loopItem{key: strconv.Itoa(i + 1), keyType: Number, value: valueText(fl, Number), elemType: Number})
+	}
+
+	return items, nil
 }
 
 // evaluate on a forNode checks which kind of for it is (range vs props) and
 // performs the necessary loop, evaluating its loop node for each element in the iterable
-// and returning the concatenation.
+// and returning the concatenation. If the iterable has no elements, or does not exist
+// and the loop has an else clause, the else clause is evaluated instead.
 func (n *forNode) evaluate(ctx *ASTContext) (string, error) {
 	logger.DefaultLogger.Node("For:", n.pattern)
 
-	a, err := n.pattern.stringValue(ctx)
+	var items []loopItem
+	var err error
+
+	if n.to != nil {
+		items, err = n.numberItems(ctx)
+	} else {
+		items, err = n.collectionItems(ctx)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	items, err = n.selectItems(ctx, items)
 
 	if err != nil {
 		return "", err
 	}
 
-	iterable := []byte(a)
 	var loopString string
 
-	if n.forType {
-		loopString = n.rangeFor(ctx, iterable)
+	if len(items) == 0 {
+		loopString, err = evaluateClause(n.elseClause, ctx)
 	} else {
-		loopString = n.propFor(ctx, iterable)
+		loopString, err = n.iterate(ctx, items)
+	}
+
+	if err != nil {
+		return "", err
 	}
+
 	return n.withChild(loopString, ctx)
 }
+
+// scoper is implemented by nodes which produce no text, but change the context of the nodes that follow them
+type scoper interface {
+	// scope returns the context for the nodes following the node
+	scope(ctx *ASTContext) (*ASTContext, error)
+}
+
+// letNode binds the value of an element to a name, which is visible to the nodes that follow it
+// in the same sequence. It produces no text of its own.
+type letNode struct {
+	baseNode
+
+	// name the value is bound to
+	name string
+
+	// value is the element whose value is bound to name
+	value element
+}
+
+// scope on a letNode evaluates its element and returns a context where name refers to the resulting value
+func (n *letNode) scope(ctx *ASTContext) (*ASTContext, error) {
+	text, tpe, err := elementText(n.value, ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ctx.withGetter(shadow(ctx.Getter, n.name, text, tpe)), nil
+}
+
+// evaluate on a letNode evaluates the following nodes in a context where name refers to the value of its element
+func (n *letNode) evaluate(ctx *ASTContext) (string, error) {
+	logger.DefaultLogger.Node("Let:", n.name)
+
+	scoped, err := n.scope(ctx)
+
+	if err != nil {
+		return "", err
+	}
+
+	return n.withChild("", scoped)
+}
+
+// includeNode represents the inclusion of another template, which is evaluated in the
+// same context as the template including it
+type includeNode struct {
+	baseNode
+
+	// path of the included template, as written in the template
+	path string
+
+	// top is the top node of the included template
+	top node
+}
+
+// evaluate on an includeNode evaluates the included template + the evaluated text of its
+// proceeding nodes
+func (n *includeNode) evaluate(ctx *ASTContext) (string, error) {
+	logger.DefaultLogger.Node("Include:", n.path)
+
+	included, err := evaluateClause(n.top, ctx)
+
+	if err != nil {
+		return "", err
+	}
+
+	return n.withChild(included, ctx)
+}
+
+// defineNode represents the definition of a macro, a parameterised block of the template which can be
+// invoked like a function in the nodes that follow it. It produces no text of its own.
+type defineNode struct {
+	baseNode
+
+	// name the macro is invoked with
+	name string
+
+	// params are the names each argument of an invocation is bound to
+	params []string
+
+	// body is the node evaluated on each invocation
+	body node
+}
+
+// scope on a defineNode returns a context where the macro can be invoked
+func (n *defineNode) scope(ctx *ASTContext) (*ASTContext, error) {
+	return ctx.withMacro(n), nil
+}
+
+// evaluate on a defineNode evaluates the following nodes in a context where the macro can be invoked
+func (n *defineNode) evaluate(ctx *ASTContext) (string, error) {
+	logger.DefaultLogger.Node("Define:", n.name)
+
+	scoped, _ := n.scope(ctx)
+	return n.withChild("", scoped)
+}
+
+// call invokes the macro with the given arguments, evaluating its body in a context where each parameter
+// refers to its argument. Arguments are only evaluated (in the context of the invocation) when their parameter
+// is used. Returns the resulting text, or an error if the number of arguments does not match the parameters.
+func (n *defineNode) call(ctx *ASTContext, args []element) (any, ElementType, error) {
+	if len(args) != len(n.params) {
+		return nil, NotExists, fmt.Errorf("Macro %s expects %d arguments, got %d", n.name, len(n.params), len(args))
+	}
+
+	getter := ctx.Getter
+
+	for i, arg := range args {
+		getter = shadowElement(getter, n.params[i], arg, ctx)
+	}
+
+	result, err := evaluateClause(n.body, ctx.withGetter(getter))
+
+	// loops can't be broken or continued from inside a macro
+	var signal *loopSignal
+	if errors.As(err, &signal) {
+		return nil, NotExists, errors.New(signal.Error())
+	}
+
+	if err != nil {
+		return nil, NotExists, err
+	}
+
+	return result, String, nil
+}
+
+// extendsNode represents a template which extends another (its parent). Evaluating it evaluates the parent
+// template, where the blocks of the extending template replace the parent's blocks with the same name.
+type extendsNode struct {
+	baseNode
+
+	// path of the parent template, as written in the template
+	path string
+
+	// parent is the top node of the extended template
+	parent node
+
+	// blocks are the blocks defined by the extending template, by name
+	blocks map[string]*blockNode
+
+	// scopes are the lets and defines at the top level of the extending template, in order
+	scopes []scoper
+}
+
+// evaluate on an extendsNode evaluates the lets and defines of the extending template, and then the parent
+// template with its blocks overridden, so the overriding blocks can use them
+func (n *extendsNode) evaluate(ctx *ASTContext) (string, error) {
+	logger.DefaultLogger.Node("Extends:", n.path)
+
+	scoped := ctx
+
+	for _, s := range n.scopes {
+		var err error
+		scoped, err = s.scope(scoped)
+
+		if err != nil {
+			return "", err
+		}
+	}
+
+	parent, err := evaluateClause(n.parent, scoped.withBlocks(n.blocks))
+
+	if err != nil {
+		return "", err
+	}
+
+	return n.withChild(parent, ctx)
+}
+
+// blockNode represents a named region of a template, which templates extending it can override
+type blockNode struct {
+	baseNode
+
+	// name of the block
+	name string
+
+	// body is the content of the block, used when it is not overridden
+	body node
+}
+
+// evaluate on a blockNode evaluates the body of the overriding block, if there is one in the context, or its
+// own body otherwise + the evaluated text of its proceeding nodes
+func (n *blockNode) evaluate(ctx *ASTContext) (string, error) {
+	logger.DefaultLogger.Node("Block:", n.name)
+
+	body := n.body
+
+	if override, isOverridden := ctx.blocks[n.name]; isOverridden {
+		body = override.body
+	}
+
+	text, err := evaluateClause(body, ctx)
+
+	if err != nil {
+		return "", err
+	}
+
+	return n.withChild(text, ctx)
+}
+
+// switchNode represents a multi-way branch on the value of an element, the subject.
+// The first case with a value equal to the subject's is evaluated, or the default clause if none is.
+type switchNode struct {
+	baseNode
+
+	// subject is the element whose value is compared against the cases
+	subject element
+
+	// cases are the possible clauses, in the order they were defined
+	cases []switchCase
+
+	// defaultClause is evaluated if no case matches the subject
+	defaultClause node
+}
+
+// switchCase is a clause of a switch, evaluated when the subject equals any of its values
+type switchCase struct {
+	values []element
+	body   node
+}
+
+// evaluate on switchNode evaluates the subject once, then evaluates the clause matching its value
+// + the evaluated text of its proceeding nodes
+func (n *switchNode) evaluate(ctx *ASTContext) (string, error) {
+	logger.DefaultLogger.Node("Switch")
+
+	subject, subjectType, err := n.subject.value(ctx)
+
+	if err != nil {
+		return "", err
+	}
+
+	clause := n.defaultClause
+
+cases:
+	for _, c := range n.cases {
+		for _, value := range c.values {
+			v, tpe, err := value.value(ctx)
+
+			if err != nil {
+				return "", err
+			}
+
+			if equalValues(subject, subjectType, v, tpe) {
+				clause = c.body
+				break cases
+			}
+		}
+	}
+
+	text, err := evaluateClause(clause, ctx)
+
+	if err != nil {
+		return "", err
+	}
+
+	return n.withChild(text, ctx)
+}